@@ -0,0 +1,9 @@
+module github.com/google-github-actions/example-workflows
+
+go 1.21
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,305 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowConfigSchema returns the JSON schema for workflow.config.json, with the types of TypeCategories
+func workflowConfigSchema() string {
+	pattern, _ := json.Marshal(typePattern())
+	return fmt.Sprintf(`{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "additionalProperties": {
+    "type": "object",
+    "required": ["starter", "type", "workflowPath", "propertiesPath"],
+    "additionalProperties": false,
+    "properties": {
+      "starter": { "type": "boolean" },
+      "type": { "type": "string", "pattern": %s },
+      "workflowPath": { "type": "string", "minLength": 1 },
+      "propertiesPath": { "type": "string", "minLength": 1 }
+    }
+  }
+}`, pattern)
+}
+
+// Workflow is the object properties for each workflow
+type Workflow struct {
+	Starter        bool   `json:"starter" toml:"starter"`
+	Type           string `json:"type" toml:"type"`
+	WorkflowPath   string `json:"workflowPath" toml:"workflowPath"`
+	PropertiesPath string `json:"propertiesPath" toml:"propertiesPath"`
+}
+
+// WorkflowConfig is the object referencing all workflow configs
+type WorkflowConfig map[string]Workflow
+
+// PropertiesConfig are the object properties for the *.properties.json and *.properties.yaml files
+type PropertiesConfig struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Creator     string   `json:"creator"`
+	IconName    string   `json:"iconName"`
+	Categories  []string `json:"categories"`
+	Aliases     []string `json:"aliases,omitempty"`
+
+	// RequiredSecrets and RequiredPermissions are listed in the README so users know what a workflow needs upfront
+	RequiredSecrets     []string `json:"requiredSecrets,omitempty"`
+	RequiredPermissions []string `json:"requiredPermissions,omitempty"`
+
+	// Variables are the env variables users set for the workflow, listed in a table in the README
+	Variables []PropertiesVariable `json:"variables,omitempty"`
+
+	// Deprecated workflows stay listed in the README with a deprecated label and are not released
+	Deprecated      bool   `json:"deprecated,omitempty"`
+	DeprecationNote string `json:"deprecationNote,omitempty"`
+
+	// Featured workflows are also listed in a section above the actions of the README
+	Featured bool `json:"featured,omitempty"`
+}
+
+// PropertiesVariable is an env variable of a workflow documented in its properties file
+type PropertiesVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// LoadWorkflowConfig loads the workflow config after validating it against workflowConfigSchema. The
+// workflow and properties paths of the config are relative to its directory and are resolved to
+// paths relative to the working directory.
+func (g *Generator) LoadWorkflowConfig(wc *WorkflowConfig) error {
+	configBytes, err := os.ReadFile(g.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	// an empty TOML config is a valid config without workflows
+	if len(bytes.TrimSpace(configBytes)) == 0 && path.Ext(g.ConfigPath) != ".toml" {
+		return errors.New("config is empty, it should be a JSON object of workflows such as {}")
+	}
+
+	var raw interface{}
+	if err := LoadConfigFile(&raw, g.ConfigPath); err != nil {
+		return err
+	}
+
+	errs, err := validateWorkflowConfigSchema(raw)
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		return fmt.Errorf("failed schema validation:\n  %s", strings.Join(messages, "\n  "))
+	}
+
+	if err := LoadConfigFile(wc, g.ConfigPath); err != nil {
+		return err
+	}
+
+	for workflowID, workflow := range *wc {
+		workflow.WorkflowPath = g.resolveConfigPath(workflow.WorkflowPath)
+		workflow.PropertiesPath = g.resolveConfigPath(workflow.PropertiesPath)
+		(*wc)[workflowID] = workflow
+	}
+
+	return nil
+}
+
+// resolveConfigPath returns a path of the workflow config relative to the working directory. It is
+// joined without cleaning, so an unclean path in the config is still reported by ValidateConfigPath.
+func (g *Generator) resolveConfigPath(p string) string {
+	configDir := path.Dir(g.ConfigPath)
+	if configDir == "." || p == "" || path.IsAbs(p) {
+		return p
+	}
+	return configDir + "/" + p
+}
+
+// ConfigRelativePath returns a path relative to the working directory as it is written in the
+// workflow config, relative to the directory of the config
+func (g *Generator) ConfigRelativePath(p string) string {
+	configDir := path.Dir(g.ConfigPath)
+	if configDir == "." || p == "" {
+		return p
+	}
+	if rel := strings.TrimPrefix(p, configDir+"/"); rel != p {
+		return rel
+	}
+	if rel, err := filepath.Rel(configDir, p); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return p
+}
+
+// validateWorkflowConfigSchema validates a decoded workflow config against workflowConfigSchema, returning
+// an error for each violation prefixed with the JSON pointer of the value, sorted by pointer
+func validateWorkflowConfigSchema(value interface{}) ([]error, error) {
+	schema, err := jsonschema.CompileString("workflow.config.schema.json", workflowConfigSchema())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if err := schema.Validate(value); err == nil {
+		return nil, nil
+	} else if !errors.As(err, &validationErr) {
+		return nil, fmt.Errorf("failed to validate schema: %w", err)
+	}
+
+	// only the leaves are reported, their parents repeat that a nested value is invalid
+	leaves := make([]jsonschema.BasicError, 0)
+	for _, basicErr := range validationErr.BasicOutput().Errors {
+		if basicErr.Error != "" && !strings.HasPrefix(basicErr.Error, "doesn't validate with") {
+			leaves = append(leaves, basicErr)
+		}
+	}
+	sort.SliceStable(leaves, func(i, j int) bool {
+		return leaves[i].InstanceLocation < leaves[j].InstanceLocation
+	})
+
+	errs := make([]error, 0, len(leaves))
+	for _, leaf := range leaves {
+		location := leaf.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		errs = append(errs, fmt.Errorf("%s: %s", location, leaf.Error))
+	}
+
+	return errs, nil
+}
+
+// LoadConfigFile unmarshals a JSON or, for .yaml and .yml files, a YAML file or, for .toml files, a TOML file into config
+func LoadConfigFile(config interface{}, filePath string) error {
+	configBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	switch path.Ext(filePath) {
+	case ".yaml", ".yml":
+		var doc interface{}
+		if err := yaml.Unmarshal(configBytes, &doc); err != nil {
+			return fmt.Errorf("failed to parse YAML: %w", err)
+		}
+
+		// decode through JSON so the same struct tags are used for every format
+		if configBytes, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("failed to convert YAML: %w", err)
+		}
+	case ".toml":
+		doc, err := decodeTOML(configBytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse TOML: %w", err)
+		}
+
+		if configBytes, err = json.Marshal(doc); err != nil {
+			return fmt.Errorf("failed to convert TOML: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return nil
+}
+
+// WriteWorkflowConfig writes the workflow config with sorted workflow IDs
+func (g *Generator) WriteWorkflowConfig(wc WorkflowConfig) error {
+	newConfigBytes, err := g.MarshalWorkflowConfig(wc)
+	if err != nil {
+		return err
+	}
+
+	if err := MkdirParent(g.ConfigPath); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(g.ConfigPath, newConfigBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write update workflow config: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalWorkflowConfig returns the canonical form of the workflow config, with sorted
+// workflow IDs and the keys of each workflow in struct order, as TOML for a .toml config
+// keeping the comments of the config on disk. Paths are written relative to the directory of the config.
+func (g *Generator) MarshalWorkflowConfig(wc WorkflowConfig) ([]byte, error) {
+	relativeConfig := make(WorkflowConfig, len(wc))
+	for workflowID, workflow := range wc {
+		workflow.WorkflowPath = g.ConfigRelativePath(workflow.WorkflowPath)
+		workflow.PropertiesPath = g.ConfigRelativePath(workflow.PropertiesPath)
+		relativeConfig[workflowID] = workflow
+	}
+	wc = relativeConfig
+
+	if path.Ext(g.ConfigPath) == ".toml" {
+		existing, err := os.ReadFile(g.ConfigPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read workflow config: %w", err)
+		}
+		return marshalTOMLWorkflowConfig(wc, existing)
+	}
+
+	configBytes, err := json.MarshalIndent(wc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("fail to marshal new workflow config: %w", err)
+	}
+
+	return append(configBytes, '\n'), nil
+}
+
+// DetectWorkflowConfigPath returns the TOML config next to the default JSON config when
+// only the TOML config exists, otherwise the JSON config
+func DetectWorkflowConfigPath(jsonPath string) string {
+	if _, err := os.Stat(jsonPath); !os.IsNotExist(err) {
+		return jsonPath
+	}
+
+	tomlPath := strings.TrimSuffix(jsonPath, path.Ext(jsonPath)) + ".toml"
+	if _, err := os.Stat(tomlPath); err == nil {
+		return tomlPath
+	}
+
+	return jsonPath
+}
+
+// PropertiesFileSuffix returns the .properties.json or .properties.yaml suffix of a properties file
+func PropertiesFileSuffix(propertiesPath string) string {
+	if strings.HasSuffix(propertiesPath, ".properties.yaml") {
+		return ".properties.yaml"
+	}
+	return ".properties.json"
+}
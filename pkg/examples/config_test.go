@@ -0,0 +1,138 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	want := PropertiesConfig{
+		Name:            "Deploy to Cloud Run",
+		Description:     "Deploy a container to Cloud Run.",
+		Creator:         "Google Cloud",
+		IconName:        "google-cloud",
+		Categories:      []string{"Cloud Run", "Deployment"},
+		Deprecated:      true,
+		DeprecationNote: "Use cloudrun-source instead.",
+		Featured:        true,
+		Variables: []PropertiesVariable{
+			{Name: "SERVICE", Description: "The Cloud Run service.", Required: true},
+			{Name: "REGION", Description: "The Cloud Run region."},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		fileName string
+		content  string
+		wantErr  string
+	}{
+		{
+			name:     "json",
+			fileName: "cloudrun.properties.json",
+			content: `{
+  "name": "Deploy to Cloud Run",
+  "description": "Deploy a container to Cloud Run.",
+  "creator": "Google Cloud",
+  "iconName": "google-cloud",
+  "categories": ["Cloud Run", "Deployment"],
+  "deprecated": true,
+  "deprecationNote": "Use cloudrun-source instead.",
+  "featured": true,
+  "variables": [
+    {"name": "SERVICE", "description": "The Cloud Run service.", "required": true},
+    {"name": "REGION", "description": "The Cloud Run region."}
+  ]
+}
+`,
+		},
+		{
+			name:     "yaml",
+			fileName: "cloudrun.properties.yaml",
+			content: `name: Deploy to Cloud Run
+description: Deploy a container to Cloud Run.
+creator: Google Cloud
+iconName: google-cloud
+categories:
+  - Cloud Run
+  - Deployment
+deprecated: true
+deprecationNote: Use cloudrun-source instead.
+featured: true
+variables:
+  - name: SERVICE
+    description: The Cloud Run service.
+    required: true
+  - name: REGION
+    description: The Cloud Run region.
+`,
+		},
+		{
+			name:     "yaml_quoted_bool",
+			fileName: "cloudrun.properties.yaml",
+			content:  "name: Deploy to Cloud Run\ndeprecated: \"true\"\n",
+			wantErr:  "failed to unmarshal: ",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{tc.fileName: tc.content})
+
+			var got PropertiesConfig
+			err := LoadConfigFile(&got, filepath.Join(dir, tc.fileName))
+			if tc.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.wantErr) {
+					t.Fatalf("LoadConfigFile error = %v, want %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfigFile: %s", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadConfigFile = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWorkflowConfigSchemaTypes(t *testing.T) {
+	for _, workflowType := range []string{"automation", "ci", "code-scanning", "deployments", "ci/go", "ci/go/v1-2", "deploymentz", "ci/Go", "ci/", "/ci", "ci.go"} {
+		errs, err := validateWorkflowConfigSchema(map[string]interface{}{
+			"deploy-app": map[string]interface{}{
+				"starter":        true,
+				"type":           workflowType,
+				"workflowPath":   "workflows/deploy-cloudrun/deploy-app.yml",
+				"propertiesPath": "properties/deploy-app.properties.json",
+			},
+		})
+		if err != nil {
+			t.Fatalf("validateWorkflowConfigSchema: %s", err)
+		}
+
+		// the schema accepts the types of TypeCategories, as IsValidType does
+		if got, want := len(errs) == 0, IsValidType(workflowType); got != want {
+			t.Errorf("schema accepts type %q = %t, IsValidType = %t: %q", workflowType, got, want, errs)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package examples loads, validates and renders the READMEs of the example workflows
+// of a repository laid out like google-github-actions/example-workflows. It is wrapped
+// by scripts/generate.go and can be imported to generate READMEs programmatically.
+package examples
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"runtime"
+)
+
+const (
+	// DefaultConfigPath is the workflow config read when Options.ConfigPath is not set
+	DefaultConfigPath = "workflow.config.json"
+	// DefaultWorkflowsDir is the directory of the action folders when Options.WorkflowsDir is not set
+	DefaultWorkflowsDir = "workflows"
+	// DefaultPropertiesDir is the directory of the properties files when Options.PropertiesDir is not set
+	DefaultPropertiesDir = "properties"
+	// DefaultTemplateDir is the directory of the templates when Options.TemplateDir is not set
+	DefaultTemplateDir = "templates"
+	// DefaultMaxNameLength is the maximum length of a properties name when Options.MaxNameLength is not set
+	DefaultMaxNameLength = 50
+
+	readmeTitle        = "Google GitHub Actions - Example Workflows"
+	readmeSectionTitle = "Available Examples"
+)
+
+// Options configures a Generator. Paths are relative to the working directory,
+// and the zero value of every field uses the same default as scripts/generate.go.
+type Options struct {
+	// ConfigPath is the workflow config, a .json or .toml file
+	ConfigPath string
+	// WorkflowsDir is the directory of the action folders
+	WorkflowsDir string
+	// PropertiesDir is the directory of the properties files
+	PropertiesDir string
+	// TemplateDir is the directory of the README and action README templates
+	TemplateDir string
+
+	// OutputFormat is the format of the README, md or html
+	OutputFormat string
+	// ReadmePath is the README written by GenerateReadme, README.<OutputFormat> by default
+	ReadmePath string
+	// ManifestPath is the JSON manifest written along with the README when set
+	ManifestPath string
+	// LinkBaseURL makes the workflow links of the README absolute when set, e.g. https://github.com/org/repo/blob/main/
+	LinkBaseURL string
+	// PreviewLines is the number of lines of each workflow embedded in the README
+	PreviewLines int
+	// IconsDir is the starter workflows icons directory properties icons are validated against when set
+	IconsDir string
+
+	// ReadmeConfigPath, CategoriesPath, TriggersPath, LintRulesPath, ProductKeywordsPath and
+	// LicenseHeaderPath are the optional files overriding the built-in README order, allowed
+	// categories and triggers, lint rules, product keywords and required license header
+	ReadmeConfigPath    string
+	CategoriesPath      string
+	TriggersPath        string
+	LintRulesPath       string
+	ProductKeywordsPath string
+	LicenseHeaderPath   string
+
+	// Check compares the README to the rendered one instead of writing it
+	Check bool
+	// CheckLinks also checks the relative links of the action READMEs
+	CheckLinks bool
+	// CheckTriggers fails validation for workflow triggers outside the allowed triggers
+	CheckTriggers bool
+	// CheckVariables also warns about the env variables of workflows that declare no variables in their properties
+	CheckVariables bool
+	// Incremental only writes the READMEs whose rendered content changed
+	Incremental bool
+	// RegenerateActionReadmes renders every action README from the action README template
+	RegenerateActionReadmes bool
+	// Split renders each action into its action README and only links to them from the README
+	Split bool
+	// StarterFirst lists the starter workflows of an action before its other workflows
+	StarterFirst bool
+	// Since only validates the workflows changed since this git ref when set
+	Since string
+	// Strict reports validation warnings as errors
+	Strict bool
+	// FailOnWarning counts validation warnings as failures while still reporting them as warnings
+	FailOnWarning bool
+	// Actionlint also runs actionlint on every workflow when it is on PATH
+	Actionlint bool
+	// MaxNameLength is the maximum length of a properties name
+	MaxNameLength int
+	// Workers is the maximum number of properties files loaded or READMEs rendered concurrently
+	Workers int
+
+	// Logger receives progress messages and validation problems, discarded when nil
+	Logger Logger
+	// Stdout receives command output such as README diffs, os.Stdout when nil
+	Stdout io.Writer
+}
+
+// Generator loads, validates and renders the example workflows configured by its Options
+type Generator struct {
+	Options
+}
+
+// NewGenerator returns a Generator for opts with the defaults of the unset options applied
+func NewGenerator(opts Options) *Generator {
+	if opts.ConfigPath == "" {
+		opts.ConfigPath = DefaultConfigPath
+	}
+	if opts.WorkflowsDir == "" {
+		opts.WorkflowsDir = DefaultWorkflowsDir
+	}
+	if opts.PropertiesDir == "" {
+		opts.PropertiesDir = DefaultPropertiesDir
+	}
+	if opts.TemplateDir == "" {
+		opts.TemplateDir = DefaultTemplateDir
+	}
+	if opts.OutputFormat == "" {
+		opts.OutputFormat = "md"
+	}
+	if opts.ReadmePath == "" {
+		opts.ReadmePath = fmt.Sprintf("README.%s", opts.OutputFormat)
+	}
+	if opts.ReadmeConfigPath == "" {
+		opts.ReadmeConfigPath = "readme.config.json"
+	}
+	if opts.CategoriesPath == "" {
+		opts.CategoriesPath = "categories.json"
+	}
+	if opts.TriggersPath == "" {
+		opts.TriggersPath = "triggers.json"
+	}
+	if opts.LintRulesPath == "" {
+		opts.LintRulesPath = "lint-rules.json"
+	}
+	if opts.ProductKeywordsPath == "" {
+		opts.ProductKeywordsPath = "product-keywords.json"
+	}
+	if opts.LicenseHeaderPath == "" {
+		opts.LicenseHeaderPath = "license-header.txt"
+	}
+	if opts.MaxNameLength == 0 {
+		opts.MaxNameLength = DefaultMaxNameLength
+	}
+	if opts.Workers < 1 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.Logger == nil {
+		opts.Logger = discardLogger{}
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+
+	return &Generator{Options: opts}
+}
+
+// readmeTemplatePath is the template of the README in the output format
+func (g *Generator) readmeTemplatePath() string {
+	return path.Join(g.TemplateDir, fmt.Sprintf("README.tmpl.%s", g.OutputFormat))
+}
+
+// actionReadmeTemplatePath is the template of the action READMEs
+func (g *Generator) actionReadmeTemplatePath() string {
+	return path.Join(g.TemplateDir, "action-README.tmpl.md")
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"fmt"
+	"io"
+)
+
+// LogLevel is the severity of a log message
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Logger logs diagnostic messages by severity, command output such as reports and diffs is written to Stdout instead
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// NewLogger returns a Logger writing the messages at or above level to out,
+// with warnings and errors prefixed by their level
+func NewLogger(out io.Writer, level LogLevel) Logger {
+	return &writerLogger{out: out, level: level}
+}
+
+// writerLogger is the Logger returned by NewLogger
+type writerLogger struct {
+	out   io.Writer
+	level LogLevel
+}
+
+func (l *writerLogger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, "", format, args...)
+}
+
+func (l *writerLogger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, "", format, args...)
+}
+
+func (l *writerLogger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, "warning: ", format, args...)
+}
+
+func (l *writerLogger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, "error: ", format, args...)
+}
+
+func (l *writerLogger) logf(level LogLevel, prefix string, format string, args ...interface{}) {
+	if level < l.level {
+		return
+	}
+	fmt.Fprintf(l.out, "%s%s\n", prefix, fmt.Sprintf(format, args...))
+}
+
+// discardLogger is the Logger of a Generator without one
+type discardLogger struct{}
+
+func (discardLogger) Debugf(format string, args ...interface{}) {}
+func (discardLogger) Infof(format string, args ...interface{})  {}
+func (discardLogger) Warnf(format string, args ...interface{})  {}
+func (discardLogger) Errorf(format string, args ...interface{}) {}
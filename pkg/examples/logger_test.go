@@ -0,0 +1,61 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	cases := []struct {
+		name  string
+		level LogLevel
+		want  string
+	}{
+		{
+			name:  "debug",
+			level: LevelDebug,
+			want:  "debug 1\ninfo 2\nwarning: warn 3\nerror: error 4\n",
+		},
+		{
+			name:  "info",
+			level: LevelInfo,
+			want:  "info 2\nwarning: warn 3\nerror: error 4\n",
+		},
+		{
+			name:  "error",
+			level: LevelError,
+			want:  "error: error 4\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := NewLogger(&out, tc.level)
+			logger.Debugf("debug %d", 1)
+			logger.Infof("info %d", 2)
+			logger.Warnf("warn %d", 3)
+			logger.Errorf("error %d", 4)
+
+			if got := out.String(); got != tc.want {
+				t.Errorf("logged %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
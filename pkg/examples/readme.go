@@ -0,0 +1,961 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// markdownLinkPattern matches the target of inline markdown links and images
+	markdownLinkPattern = regexp.MustCompile(`\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+)
+
+// ReadmeTemplateConfig is the template config used for the index README template
+type ReadmeTemplateConfig struct {
+	Title           string
+	SectionTitle    string
+	TableOfContents []ReadmeTOCEntry
+	Featured        []ReadmeFeaturedWorkflow
+	Actions         []ReadmeAction
+	// Split only links to the action READMEs instead of listing the workflows of each action
+	Split bool
+}
+
+// ReadmeTOCEntry is a table of contents link used for the index README template
+type ReadmeTOCEntry struct {
+	Name   string
+	Anchor string
+}
+
+// ReadmeAction is the action template config used for the index README template
+type ReadmeAction struct {
+	Name       string
+	Path       string
+	ReadMePath string
+	// ReadMeURL links to the action README from the README, relative to the directory of the config
+	ReadMeURL  string
+	Workflows  []ReadmeWorkflow
+	Groups     []ReadmeGroup
+	Categories []string
+}
+
+// ReadmeGroup is the workflows of an action sharing an intermediate path, used for the index README template
+type ReadmeGroup struct {
+	Name      string
+	Workflows []ReadmeWorkflow
+}
+
+// ReadmeWorkflow is the workflow config used for the index README template
+type ReadmeWorkflow struct {
+	Name                string
+	RelativeName        string
+	Group               string
+	Description         string
+	Starter             bool
+	Categories          []string
+	Aliases             []string
+	RequiredSecrets     []string
+	RequiredPermissions []string
+	Variables           []PropertiesVariable
+	Deprecated          bool
+	DeprecationNote     string
+	Featured            bool
+	WorkflowPath        string
+	WorkflowURL         string
+	Preview             string
+	PropertiesPath      string
+}
+
+// ReadmeFeaturedWorkflow is a featured workflow listed above the actions of the index README, with its action
+type ReadmeFeaturedWorkflow struct {
+	ReadmeWorkflow
+	ActionName       string
+	ActionAnchor     string
+	ActionReadMePath string
+}
+
+// ActionReadmeTemplateConfig is the go template config used for the action README template,
+// the same section of the action as in the index README
+type ActionReadmeTemplateConfig struct {
+	Name       string
+	Categories []string
+	Workflows  []ActionReadmeWorkflow
+	Groups     []ActionReadmeGroup
+}
+
+// ActionReadmeGroup is the workflows of an action sharing an intermediate path, used for the action README template
+type ActionReadmeGroup struct {
+	Name      string
+	Workflows []ActionReadmeWorkflow
+}
+
+// ActionReadmeWorkflow is a workflow listed in an action README, with its path relative to the action
+type ActionReadmeWorkflow struct {
+	Name                string
+	Description         string
+	Starter             bool
+	Aliases             []string
+	RequiredSecrets     []string
+	RequiredPermissions []string
+	Variables           []PropertiesVariable
+	Deprecated          bool
+	DeprecationNote     string
+	Path                string
+	// URL links to the workflow from the action README, which is Path unless LinkBaseURL is set
+	URL     string
+	Preview string
+}
+
+// ReadmeConfig is the optional README ordering and section title loaded from readme.config.json
+type ReadmeConfig struct {
+	Order        []string `json:"order"`
+	SectionTitle string   `json:"sectionTitle"`
+}
+
+// ReadmeSummary is the counts printed after generating the README
+type ReadmeSummary struct {
+	Actions   int `json:"actions"`
+	Workflows int `json:"workflows"`
+	Starters  int `json:"starters"`
+}
+
+// ManifestWorkflow is the object written for each workflow in the JSON manifest
+type ManifestWorkflow struct {
+	ActionName   string   `json:"actionName"`
+	WorkflowName string   `json:"workflowName"`
+	Description  string   `json:"description"`
+	RelativeName string   `json:"relativeName"`
+	Starter      bool     `json:"starter"`
+	Categories   []string `json:"categories"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Deprecated   bool     `json:"deprecated,omitempty"`
+	Featured     bool     `json:"featured,omitempty"`
+	WorkflowPath string   `json:"workflowPath"`
+
+	RequiredSecrets     []string `json:"requiredSecrets,omitempty"`
+	RequiredPermissions []string `json:"requiredPermissions,omitempty"`
+}
+
+// PropertiesTemplateConfig is the go template config used for the workflow properties template
+type PropertiesTemplateConfig struct {
+	WorkflowID string
+	// Title is the humanized workflow ID, e.g. Cloud Run Docker for cloudrun-docker
+	Title      string
+	Type       string
+	Creator    string
+	IconName   string
+	Categories []string
+}
+
+// LoadedPropertiesFile is the result of loading a workflow properties file
+type LoadedPropertiesFile struct {
+	Properties PropertiesConfig
+	Err        error
+}
+
+// GenerateReadme renders the main readme and individual action readmes, returning the counts of the README.
+// With Check it only compares the README to the rendered one and returns an empty summary.
+func (g *Generator) GenerateReadme(ctx context.Context) (ReadmeSummary, error) {
+	if g.OutputFormat != "md" && g.OutputFormat != "html" {
+		return ReadmeSummary{}, fmt.Errorf("invalid OUTPUT_FORMAT %s, should be md or html", g.OutputFormat)
+	}
+
+	// the workflows of each action are only listed in the action READMEs with Split
+	regenerateActionReadmes := g.RegenerateActionReadmes || g.Split
+
+	// missing action READMEs are created when they are regenerated
+	readmeTemplateConfigs, err := g.LoadReadmeTemplateConfig(regenerateActionReadmes)
+	if err != nil {
+		return ReadmeSummary{}, err
+	}
+	sortedActions := readmeTemplateConfigs.Actions
+
+	if g.Check {
+		if g.Split {
+			if err := g.checkActionReadmes(sortedActions); err != nil {
+				return ReadmeSummary{}, err
+			}
+		}
+		g.Logger.Debugf("checking %s against template %s", g.ReadmePath, g.readmeTemplatePath())
+		return ReadmeSummary{}, g.CheckReadme(readmeTemplateConfigs)
+	}
+
+	if regenerateActionReadmes {
+		if err := g.renderActionReadmes(sortedActions); err != nil {
+			return ReadmeSummary{}, err
+		}
+	}
+
+	g.Logger.Debugf("rendering template %s to %s", g.readmeTemplatePath(), g.ReadmePath)
+	if g.Incremental {
+		changed, err := renderTemplateFileIfChanged(g.readmeTemplatePath(), g.ReadmePath, readmeTemplateConfigs)
+		if err != nil {
+			return ReadmeSummary{}, fmt.Errorf("failed to render readme template: %w", err)
+		}
+		if changed {
+			g.Logger.Infof("updated %s", g.ReadmePath)
+		} else {
+			g.Logger.Infof("skipped %s, content is unchanged", g.ReadmePath)
+		}
+	} else if err := RenderTemplateFile(g.readmeTemplatePath(), g.ReadmePath, readmeTemplateConfigs); err != nil {
+		return ReadmeSummary{}, fmt.Errorf("failed to render readme template: %w", err)
+	}
+
+	if g.ManifestPath != "" {
+		g.Logger.Debugf("writing manifest %s", g.ManifestPath)
+		if err := g.writeManifest(sortedActions); err != nil {
+			return ReadmeSummary{}, fmt.Errorf("failed to write manifest: %w", err)
+		}
+	}
+
+	return summarizeReadme(sortedActions), nil
+}
+
+// LoadReadmeTemplateConfig loads the sorted actions and their workflows into the index README template config
+func (g *Generator) LoadReadmeTemplateConfig(skipActionReadmes bool) (ReadmeTemplateConfig, error) {
+	readmeCfg, err := g.LoadReadmeConfig()
+	if err != nil {
+		return ReadmeTemplateConfig{}, fmt.Errorf("failed to load readme config: %w", err)
+	}
+
+	sortedActions, err := g.LoadReadmeActions(readmeCfg, skipActionReadmes)
+	if err != nil {
+		return ReadmeTemplateConfig{}, err
+	}
+
+	brokenLinks, err := g.findBrokenLinks(sortedActions, skipActionReadmes, g.CheckLinks)
+	if err != nil {
+		return ReadmeTemplateConfig{}, err
+	}
+	if len(brokenLinks) > 0 {
+		for _, brokenLink := range brokenLinks {
+			g.Logger.Errorf("validation failed for generate readme: %s", brokenLink)
+		}
+		return ReadmeTemplateConfig{}, fmt.Errorf("found %d broken link(s)", len(brokenLinks))
+	}
+
+	tableOfContents := make([]ReadmeTOCEntry, 0, len(sortedActions))
+	for _, action := range sortedActions {
+		tableOfContents = append(tableOfContents, ReadmeTOCEntry{
+			Name:   action.Name,
+			Anchor: GithubAnchor(action.Name),
+		})
+	}
+
+	return ReadmeTemplateConfig{
+		Title:           readmeTitle,
+		SectionTitle:    readmeCfg.SectionTitle,
+		TableOfContents: tableOfContents,
+		Featured:        featuredWorkflows(sortedActions),
+		Actions:         sortedActions,
+		Split:           g.Split,
+	}, nil
+}
+
+// featuredWorkflows returns the featured workflows of every action sorted by name
+func featuredWorkflows(actions []ReadmeAction) []ReadmeFeaturedWorkflow {
+	featured := make([]ReadmeFeaturedWorkflow, 0)
+	for _, action := range actions {
+		for _, workflow := range action.Workflows {
+			if workflow.Featured {
+				featured = append(featured, ReadmeFeaturedWorkflow{
+					ReadmeWorkflow:   workflow,
+					ActionName:       action.Name,
+					ActionAnchor:     GithubAnchor(action.Name),
+					ActionReadMePath: action.ReadMeURL,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(featured, func(i, j int) bool {
+		return strings.ToLower(featured[i].Name) < strings.ToLower(featured[j].Name)
+	})
+
+	return featured
+}
+
+// findBrokenLinks returns an error for each action README and workflow file linked from the index README
+// that does not exist, and with checkActionReadmes for each relative link in an action README to a missing file
+func (g *Generator) findBrokenLinks(actions []ReadmeAction, skipActionReadmes bool, checkActionReadmes bool) ([]error, error) {
+	brokenLinks := make([]error, 0)
+	for _, action := range actions {
+		links := make([]string, 0, len(action.Workflows)+1)
+		// missing action READMEs are created when they are regenerated
+		if !skipActionReadmes {
+			links = append(links, action.ReadMePath)
+		}
+		for _, workflow := range action.Workflows {
+			links = append(links, workflow.WorkflowPath)
+		}
+
+		for _, link := range links {
+			if _, err := os.Stat(link); os.IsNotExist(err) {
+				brokenLinks = append(brokenLinks, fmt.Errorf("%s links to %s, which does not exist", g.ReadmePath, link))
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to validate %s exists: %w", link, err)
+			}
+		}
+
+		if !checkActionReadmes || skipActionReadmes {
+			continue
+		}
+
+		contents, err := os.ReadFile(action.ReadMePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", action.ReadMePath, err)
+		}
+
+		for _, match := range markdownLinkPattern.FindAllStringSubmatch(string(contents), -1) {
+			target := match[1]
+			if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") || strings.HasPrefix(target, "#") {
+				continue
+			}
+
+			linkPath := path.Join(path.Dir(action.ReadMePath), strings.SplitN(target, "#", 2)[0])
+			if _, err := os.Stat(linkPath); os.IsNotExist(err) {
+				brokenLinks = append(brokenLinks, fmt.Errorf("%s links to %s, which does not exist", action.ReadMePath, target))
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to validate %s exists: %w", linkPath, err)
+			}
+		}
+	}
+
+	return brokenLinks, nil
+}
+
+// isMissingLink reports whether err is about one of the linked files not existing
+func isMissingLink(err error, links ...string) bool {
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || !errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+
+	for _, link := range links {
+		if link != "" && pathErr.Path == link {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateActionReadmes renders the README of every action from the action README template
+func (g *Generator) GenerateActionReadmes(ctx context.Context) error {
+	sortedActions, err := g.LoadReadmeActions(ReadmeConfig{}, true)
+	if err != nil {
+		return err
+	}
+
+	return g.renderActionReadmes(sortedActions)
+}
+
+// renderActionReadmes concurrently renders the action READMEs using a bounded pool of workers,
+// reporting the errors of every action in action order
+func (g *Generator) renderActionReadmes(actions []ReadmeAction) error {
+	errs := make([]error, len(actions))
+	changed := make([]bool, len(actions))
+
+	var wg sync.WaitGroup
+	queue := make(chan int)
+
+	for i := 0; i < g.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				changed[i], errs[i] = g.renderActionReadme(actions[i])
+			}
+		}()
+	}
+
+	// logged while queueing rather than by the workers, so the messages are in action order
+	for i := range actions {
+		g.Logger.Debugf("rendering template %s to %s", g.actionReadmeTemplatePath(), actions[i].ReadMePath)
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			g.Logger.Errorf("%s", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to render %d action README(s)", failed)
+	}
+
+	if g.Incremental {
+		updated := 0
+		for i, action := range actions {
+			if changed[i] {
+				g.Logger.Infof("updated %s", action.ReadMePath)
+				updated++
+			} else {
+				g.Logger.Debugf("skipped %s, content is unchanged", action.ReadMePath)
+			}
+		}
+		g.Logger.Infof("updated %d action README(s), skipped %d unchanged", updated, len(actions)-updated)
+	}
+
+	return nil
+}
+
+// workflowLink returns the link to workflowPath rendered in the README, which is workflowPath relative
+// to the repository root unless baseURL is set, e.g. https://github.com/org/repo/blob/main/
+func workflowLink(baseURL string, workflowPath string) string {
+	if baseURL == "" {
+		return workflowPath
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(workflowPath, "/")
+}
+
+// workflowPreview returns up to lines lines of a workflow file embedded in the README, skipping its license header
+func workflowPreview(workflowPath string, lines int) (string, error) {
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", workflowPath, err)
+	}
+
+	fileLines := stripLicenseHeader(strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"))
+	if len(fileLines) > lines {
+		fileLines = fileLines[:lines]
+	}
+	return strings.TrimRight(strings.Join(fileLines, "\n"), "\n "), nil
+}
+
+// stripLicenseHeader returns the lines of a workflow file after its license header, a leading comment block
+// starting with a copyright notice, and the blank lines following it
+func stripLicenseHeader(fileLines []string) []string {
+	if len(fileLines) == 0 || !strings.Contains(strings.ToLower(fileLines[0]), "copyright") {
+		return fileLines
+	}
+
+	headerEnd := 0
+	for headerEnd < len(fileLines) && strings.HasPrefix(fileLines[headerEnd], "#") {
+		headerEnd++
+	}
+	for headerEnd < len(fileLines) && strings.TrimSpace(fileLines[headerEnd]) == "" {
+		headerEnd++
+	}
+	return fileLines[headerEnd:]
+}
+
+// LoadReadmeActions loads and validates every workflow, returning the actions of the README in the
+// order of readmeCfg. Missing action READMEs are not reported when skipActionReadmes is set.
+func (g *Generator) LoadReadmeActions(readmeCfg ReadmeConfig, skipActionReadmes bool) ([]ReadmeAction, error) {
+	var wfConfig WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wfConfig); err != nil {
+		return nil, fmt.Errorf("failed to load workflow config %s: %w", g.ConfigPath, err)
+	}
+
+	categories, err := g.LoadValidCategories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load valid categories: %w", err)
+	}
+
+	var triggers map[string]bool
+	if g.CheckTriggers {
+		if triggers, err = g.loadValidTriggers(); err != nil {
+			return nil, fmt.Errorf("failed to load valid triggers: %w", err)
+		}
+	}
+
+	if g.PreviewLines < 0 {
+		return nil, fmt.Errorf("invalid preview lines %d, should be 0 or a positive number of lines", g.PreviewLines)
+	}
+
+	hasInvalidConfigs := false
+	sortedWorkflowsIDs := SortedWorkflowIDs(wfConfig)
+	readmeActions := map[string]ReadmeAction{}
+	workflowNames := map[string][]string{}
+	loadedProperties := g.LoadPropertiesFiles(wfConfig, sortedWorkflowsIDs)
+
+	var changedWorkflows map[string]bool
+	if g.Since != "" {
+		changedWorkflows = g.getChangedWorkflowIDs(wfConfig, g.Since)
+	}
+
+	for _, workflowID := range sortedWorkflowsIDs {
+		workflow := wfConfig[workflowID]
+		shouldValidate := changedWorkflows == nil || changedWorkflows[workflowID]
+
+		if errs := g.validateConfigPaths(workflow); len(errs) > 0 {
+			for _, err := range errs {
+				g.Logger.Errorf("validation failed for generate readme workflow %s: %s", workflowID, err)
+			}
+			hasInvalidConfigs = true
+			continue
+		}
+
+		// This should be at least <workflows dir>/action-name/workflow-name.yml, but can be longer
+		actionPath, err := g.ActionPathOf(workflow.WorkflowPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// links are relative to the directory of the config, like the paths written in it
+		workflowLinkPath := g.ConfigRelativePath(workflow.WorkflowPath)
+		actionName := path.Base(actionPath)
+		actionReadMePath := path.Join(actionPath, "README.md")
+		workflowFileName := path.Base(workflowLinkPath)
+		workflowRelativeName := strings.TrimSuffix(workflowFileName, filepath.Ext(workflowFileName))
+
+		workflowGroup := workflowGroupOf(workflow.WorkflowPath, actionPath)
+
+		if shouldValidate {
+			validateAction := ReadmeAction{ReadMePath: actionReadMePath}
+			if skipActionReadmes {
+				validateAction.ReadMePath = ""
+			}
+
+			errs := make([]error, 0)
+			for _, err := range g.validateGenerateReadme(workflow, validateAction, triggers) {
+				// missing link targets are reported together as broken links once every action is loaded
+				if !isMissingLink(err, workflow.WorkflowPath, validateAction.ReadMePath) {
+					errs = append(errs, err)
+				}
+			}
+			if len(errs) > 0 {
+				for _, err := range errs {
+					g.Logger.Errorf("validation failed for generate readme workflow %s: %s", workflowID, err)
+				}
+				hasInvalidConfigs = true
+				continue
+			}
+		}
+
+		loaded := loadedProperties[workflowID]
+		if loaded.Err != nil {
+			return nil, fmt.Errorf("failed to load properties file %s: %w", workflow.PropertiesPath, loaded.Err)
+		}
+		g.Logger.Debugf("loaded properties file %s", workflow.PropertiesPath)
+		properties := loaded.Properties
+
+		if shouldValidate {
+			errs := append(g.ValidateProperties(properties, categories), validateStarterProperties(workflow, properties)...)
+			if len(errs) > 0 {
+				for _, err := range errs {
+					g.Logger.Errorf("validation failed for generate readme workflow %s: %s", workflowID, err)
+				}
+				hasInvalidConfigs = true
+				continue
+			}
+		}
+
+		workflowNames[properties.Name] = append(workflowNames[properties.Name], workflowID)
+
+		actionData, hasKey := readmeActions[actionName]
+		if !hasKey {
+			emptyWorkflows := make([]ReadmeWorkflow, 0)
+			actionData = ReadmeAction{
+				Name:       actionName,
+				Path:       actionPath,
+				ReadMePath: actionReadMePath,
+				ReadMeURL:  g.ConfigRelativePath(actionReadMePath),
+				Workflows:  emptyWorkflows,
+			}
+		}
+
+		var preview string
+		if g.PreviewLines > 0 {
+			if preview, err = workflowPreview(workflow.WorkflowPath, g.PreviewLines); err != nil && !isMissingLink(err, workflow.WorkflowPath) {
+				return nil, err
+			}
+		}
+
+		actionData.Workflows = append(actionData.Workflows, ReadmeWorkflow{
+			Name:                properties.Name,
+			RelativeName:        workflowRelativeName,
+			Group:               workflowGroup,
+			Description:         properties.Description,
+			Starter:             workflow.Starter,
+			Categories:          properties.Categories,
+			Aliases:             properties.Aliases,
+			RequiredSecrets:     properties.RequiredSecrets,
+			RequiredPermissions: properties.RequiredPermissions,
+			Variables:           properties.Variables,
+			Deprecated:          properties.Deprecated,
+			DeprecationNote:     properties.DeprecationNote,
+			Featured:            properties.Featured,
+			WorkflowPath:        workflow.WorkflowPath,
+			WorkflowURL:         workflowLink(g.LinkBaseURL, workflowLinkPath),
+			Preview:             preview,
+			PropertiesPath:      workflow.PropertiesPath,
+		})
+
+		readmeActions[actionData.Name] = actionData
+	}
+
+	for _, err := range validateUniqueNames(workflowNames) {
+		g.Logger.Errorf("validation failed for generate readme: %s", err)
+		hasInvalidConfigs = true
+	}
+
+	for _, actionName := range readmeCfg.Order {
+		if _, ok := readmeActions[actionName]; !ok {
+			g.Logger.Errorf("validation failed for generate readme: action %s in the order of %s has no workflows", actionName, g.ReadmeConfigPath)
+			hasInvalidConfigs = true
+		}
+	}
+
+	if hasInvalidConfigs {
+		return nil, fmt.Errorf("failed to process invalid configs")
+	}
+
+	sortedActions := SortedActions(readmeActions, readmeCfg.Order)
+	for i := range sortedActions {
+		SortReadmeWorkflows(sortedActions[i].Workflows, g.StarterFirst)
+		sortedActions[i].Groups = GroupReadmeWorkflows(sortedActions[i].Workflows)
+		sortedActions[i].Categories = ActionCategories(sortedActions[i].Workflows)
+	}
+
+	return sortedActions, nil
+}
+
+// workflowGroupOf returns the group of a workflow in its action, workflows nested deeper than the
+// action folder are grouped by their intermediate path and the others are in the unnamed group
+func workflowGroupOf(workflowPath string, actionPath string) string {
+	group := path.Dir(strings.TrimPrefix(workflowPath, actionPath+"/"))
+	if group == "." {
+		return ""
+	}
+	return group
+}
+
+// summarizeReadme counts the actions, workflows and starter workflows in the README
+func summarizeReadme(actions []ReadmeAction) ReadmeSummary {
+	summary := ReadmeSummary{Actions: len(actions)}
+	for _, action := range actions {
+		summary.Workflows += len(action.Workflows)
+		for _, workflow := range action.Workflows {
+			if workflow.Starter {
+				summary.Starters++
+			}
+		}
+	}
+
+	return summary
+}
+
+// CheckReadme renders the README in memory and compares it to the existing file without writing it
+func (g *Generator) CheckReadme(readmeTemplateConfigs ReadmeTemplateConfig) error {
+	var rendered bytes.Buffer
+	if err := RenderTemplate(g.readmeTemplatePath(), &rendered, readmeTemplateConfigs); err != nil {
+		return fmt.Errorf("failed to render readme template: %w", err)
+	}
+
+	existing, err := os.ReadFile(g.ReadmePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", g.ReadmePath, err)
+	}
+
+	if bytes.Equal(existing, rendered.Bytes()) {
+		return nil
+	}
+
+	diff := LineDiff(strings.Split(string(existing), "\n"), strings.Split(rendered.String(), "\n"))
+	for _, line := range diff {
+		fmt.Fprintln(g.Stdout, line)
+	}
+
+	return fmt.Errorf("%s is out of date, %d line(s) differ, run the following command to update it: go run scripts/generate.go readme", g.ReadmePath, len(diff))
+}
+
+// checkActionReadmes checks every action README is up to date with the action README template,
+// printing the diff of each outdated one
+func (g *Generator) checkActionReadmes(actions []ReadmeAction) error {
+	outdated := 0
+	for _, action := range actions {
+		var rendered bytes.Buffer
+		if err := RenderTemplate(g.actionReadmeTemplatePath(), &rendered, g.newActionReadmeTemplateConfig(action)); err != nil {
+			return fmt.Errorf("failed to render action README %s: %w", action.ReadMePath, err)
+		}
+
+		existing, err := os.ReadFile(action.ReadMePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", action.ReadMePath, err)
+		}
+
+		if bytes.Equal(existing, rendered.Bytes()) {
+			continue
+		}
+
+		outdated++
+		fmt.Fprintf(g.Stdout, "--- %s\n+++ %s\n", action.ReadMePath, action.ReadMePath)
+		for _, line := range LineDiff(strings.Split(string(existing), "\n"), strings.Split(rendered.String(), "\n")) {
+			fmt.Fprintln(g.Stdout, line)
+		}
+	}
+
+	if outdated > 0 {
+		return fmt.Errorf("%d action README(s) are out of date, run the following command to update them: go run scripts/generate.go readme --split", outdated)
+	}
+
+	return nil
+}
+
+// writeManifest writes a JSON manifest of every workflow in the same order as the README to the manifest path
+func (g *Generator) writeManifest(actions []ReadmeAction) error {
+	manifest := make([]ManifestWorkflow, 0)
+	for _, action := range actions {
+		for _, workflow := range action.Workflows {
+			categories := workflow.Categories
+			if categories == nil {
+				categories = make([]string, 0)
+			}
+
+			manifest = append(manifest, ManifestWorkflow{
+				ActionName:   action.Name,
+				WorkflowName: workflow.Name,
+				Description:  workflow.Description,
+				RelativeName: workflow.RelativeName,
+				Starter:      workflow.Starter,
+				Categories:   categories,
+				Aliases:      workflow.Aliases,
+				Deprecated:   workflow.Deprecated,
+				Featured:     workflow.Featured,
+				WorkflowPath: g.ConfigRelativePath(workflow.WorkflowPath),
+
+				RequiredSecrets:     workflow.RequiredSecrets,
+				RequiredPermissions: workflow.RequiredPermissions,
+			})
+		}
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	if err := MkdirParent(g.ManifestPath); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(g.ManifestPath, manifestBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest file %s: %w", g.ManifestPath, err)
+	}
+
+	return nil
+}
+
+// LoadReadmeConfig loads the README ordering and section title from readme.config.json when it exists
+func (g *Generator) LoadReadmeConfig() (ReadmeConfig, error) {
+	var cfg ReadmeConfig
+	if _, err := os.Stat(g.ReadmeConfigPath); err == nil {
+		if err := LoadConfigFile(&cfg, g.ReadmeConfigPath); err != nil {
+			return cfg, fmt.Errorf("failed to load %s: %w", g.ReadmeConfigPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return cfg, fmt.Errorf("failed to validate %s exists: %w", g.ReadmeConfigPath, err)
+	}
+
+	if cfg.SectionTitle == "" {
+		cfg.SectionTitle = readmeSectionTitle
+	}
+
+	return cfg, nil
+}
+
+// LoadPropertiesFiles concurrently loads the properties file of each workflow
+// using a bounded pool of workers, keyed by workflow ID
+func (g *Generator) LoadPropertiesFiles(wfConfig WorkflowConfig, workflowIDs []string) map[string]LoadedPropertiesFile {
+	results := make(map[string]LoadedPropertiesFile, len(workflowIDs))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan string)
+
+	for i := 0; i < g.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for workflowID := range queue {
+				var properties PropertiesConfig
+				err := LoadConfigFile(&properties, wfConfig[workflowID].PropertiesPath)
+
+				mu.Lock()
+				results[workflowID] = LoadedPropertiesFile{Properties: properties, Err: err}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, workflowID := range workflowIDs {
+		queue <- workflowID
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// EnsureActionReadme renders the action README listing the workflows of the action in wc
+// when it is missing or empty, existing content is never overwritten
+func (g *Generator) EnsureActionReadme(wc WorkflowConfig, actionName string, actionPath string) error {
+	missing, err := IsActionReadmeMissing(path.Join(actionPath, "README.md"))
+	if err != nil || !missing {
+		return err
+	}
+
+	return g.RenderActionReadme(wc, actionName, actionPath)
+}
+
+// RenderActionReadme renders the action README listing the workflows of the action in wc,
+// overwriting any existing README
+func (g *Generator) RenderActionReadme(wc WorkflowConfig, actionName string, actionPath string) error {
+	workflows := make([]ReadmeWorkflow, 0)
+	for _, workflowID := range SortedWorkflowIDs(wc) {
+		workflow := wc[workflowID]
+		if !strings.HasPrefix(workflow.WorkflowPath, actionPath+"/") {
+			continue
+		}
+
+		var properties PropertiesConfig
+		if err := LoadConfigFile(&properties, workflow.PropertiesPath); err != nil {
+			return fmt.Errorf("failed to load properties file %s: %w", workflow.PropertiesPath, err)
+		}
+
+		var preview string
+		if g.PreviewLines > 0 {
+			var err error
+			if preview, err = workflowPreview(workflow.WorkflowPath, g.PreviewLines); err != nil && !isMissingLink(err, workflow.WorkflowPath) {
+				return err
+			}
+		}
+
+		workflows = append(workflows, ReadmeWorkflow{
+			Name:                properties.Name,
+			Group:               workflowGroupOf(workflow.WorkflowPath, actionPath),
+			Description:         properties.Description,
+			Starter:             workflow.Starter,
+			Categories:          properties.Categories,
+			Aliases:             properties.Aliases,
+			RequiredSecrets:     properties.RequiredSecrets,
+			RequiredPermissions: properties.RequiredPermissions,
+			Variables:           properties.Variables,
+			Deprecated:          properties.Deprecated,
+			DeprecationNote:     properties.DeprecationNote,
+			WorkflowPath:        workflow.WorkflowPath,
+			Preview:             preview,
+		})
+	}
+	SortReadmeWorkflows(workflows, g.StarterFirst)
+
+	_, err := g.renderActionReadme(ReadmeAction{
+		Name:       actionName,
+		Path:       actionPath,
+		ReadMePath: path.Join(actionPath, "README.md"),
+		Workflows:  workflows,
+		Groups:     GroupReadmeWorkflows(workflows),
+		Categories: ActionCategories(workflows),
+	})
+	return err
+}
+
+// renderActionReadme renders the action README template for an action, overwriting any existing content,
+// and reports whether the file was written, which with Incremental is only when its content changed
+func (g *Generator) renderActionReadme(action ReadmeAction) (bool, error) {
+	templateConfig := g.newActionReadmeTemplateConfig(action)
+
+	if g.Incremental {
+		changed, err := renderTemplateFileIfChanged(g.actionReadmeTemplatePath(), action.ReadMePath, templateConfig)
+		if err != nil {
+			return false, fmt.Errorf("failed to render action README %s: %w", action.ReadMePath, err)
+		}
+		return changed, nil
+	}
+
+	if err := RenderTemplateFile(g.actionReadmeTemplatePath(), action.ReadMePath, templateConfig); err != nil {
+		return false, fmt.Errorf("failed to render action README %s: %w", action.ReadMePath, err)
+	}
+
+	return true, nil
+}
+
+// newActionReadmeTemplateConfig returns the action README template config of an action, with the
+// workflow links relative to the action folder unless LinkBaseURL makes them absolute
+func (g *Generator) newActionReadmeTemplateConfig(action ReadmeAction) ActionReadmeTemplateConfig {
+	actionWorkflows := func(readmeWorkflows []ReadmeWorkflow) []ActionReadmeWorkflow {
+		workflows := make([]ActionReadmeWorkflow, 0, len(readmeWorkflows))
+		for _, workflow := range readmeWorkflows {
+			relativePath := strings.TrimPrefix(workflow.WorkflowPath, action.Path+"/")
+			url := relativePath
+			if g.LinkBaseURL != "" {
+				url = workflowLink(g.LinkBaseURL, g.ConfigRelativePath(workflow.WorkflowPath))
+			}
+
+			workflows = append(workflows, ActionReadmeWorkflow{
+				Name:                workflow.Name,
+				Description:         workflow.Description,
+				Starter:             workflow.Starter,
+				Aliases:             workflow.Aliases,
+				RequiredSecrets:     workflow.RequiredSecrets,
+				RequiredPermissions: workflow.RequiredPermissions,
+				Variables:           workflow.Variables,
+				Deprecated:          workflow.Deprecated,
+				DeprecationNote:     workflow.DeprecationNote,
+				Path:                relativePath,
+				URL:                 url,
+				Preview:             workflow.Preview,
+			})
+		}
+		return workflows
+	}
+
+	groups := make([]ActionReadmeGroup, 0, len(action.Groups))
+	for _, group := range action.Groups {
+		groups = append(groups, ActionReadmeGroup{
+			Name:      group.Name,
+			Workflows: actionWorkflows(group.Workflows),
+		})
+	}
+
+	return ActionReadmeTemplateConfig{
+		Name:       action.Name,
+		Categories: action.Categories,
+		Workflows:  actionWorkflows(action.Workflows),
+		Groups:     groups,
+	}
+}
+
+// IsActionReadmeMissing reports whether the action README does not exist or is empty
+func IsActionReadmeMissing(actionReadMePath string) (bool, error) {
+	info, err := os.Stat(actionReadMePath)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to validate %s exists: %w", actionReadMePath, err)
+	}
+
+	return info.Size() == 0, nil
+}
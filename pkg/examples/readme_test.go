@@ -0,0 +1,454 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testWorkflow is a workflow long enough to not be reported as the generated stub
+const testWorkflow = `name: Deploy to Cloud Run
+
+on:
+  push:
+    branches:
+      - main
+
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`
+
+// writeTestFiles writes files relative to dir, creating their parent directories
+func writeTestFiles(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		filePath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// chdir changes the working directory to dir for the rest of the test
+func chdir(t testing.TB, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// newTestRepo writes a repository with the workflows of two actions and returns its directory
+func newTestRepo(t testing.TB) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"workflow.config.json": `{
+  "cloudrun-docker": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-cloudrun/cloudrun-docker.yml",
+    "propertiesPath": "properties/cloudrun-docker.properties.json"
+  },
+  "cloudrun-source": {
+    "starter": false,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-cloudrun/cloudrun-source.yml",
+    "propertiesPath": "properties/cloudrun-source.properties.json"
+  },
+  "appengine": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-appengine/appengine.yml",
+    "propertiesPath": "properties/appengine.properties.json"
+  }
+}
+`,
+		"workflows/deploy-cloudrun/README.md":           "# deploy-cloudrun\n",
+		"workflows/deploy-cloudrun/cloudrun-docker.yml": testWorkflow,
+		"workflows/deploy-cloudrun/cloudrun-source.yml": strings.Replace(testWorkflow, "name: Deploy to Cloud Run", "name: Deploy to Cloud Run from source", 1),
+		"workflows/deploy-appengine/README.md":          "# deploy-appengine\n",
+		"workflows/deploy-appengine/appengine.yml":      strings.Replace(testWorkflow, "name: Deploy to Cloud Run", "name: Deploy to App Engine", 1),
+		"properties/cloudrun-docker.properties.json":    `{"name": "Deploy to Cloud Run with Docker", "description": "Build a container and deploy it to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run", "Deployment"]}`,
+		"properties/cloudrun-source.properties.json":    `{"name": "Deploy to Cloud Run from source", "description": "Deploy source code to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run", "Serverless"]}`,
+		"properties/appengine.properties.json":          `{"name": "Deploy to App Engine", "description": "Deploy an app to App Engine.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Deployment"]}`,
+		"templates/README.tmpl.md":                      "{{range .Actions}}{{.Name}}:{{range .Workflows}} {{.RelativeName}}{{if .Starter}}*{{end}}{{end}}\n{{end}}",
+		"templates/action-README.tmpl.md":               "# {{.Name}}\n",
+	})
+
+	return dir
+}
+
+func TestGenerateReadme(t *testing.T) {
+	cases := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "sorted_by_name",
+			want: "deploy-appengine: appengine*\ndeploy-cloudrun: cloudrun-source cloudrun-docker*\n",
+		},
+		{
+			name: "starter_first",
+			opts: Options{StarterFirst: true},
+			want: "deploy-appengine: appengine*\ndeploy-cloudrun: cloudrun-docker* cloudrun-source\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, newTestRepo(t))
+
+			summary, err := NewGenerator(tc.opts).GenerateReadme(context.Background())
+			if err != nil {
+				t.Fatalf("GenerateReadme: %s", err)
+			}
+
+			got, err := os.ReadFile("README.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("README.md = %q, want %q", got, tc.want)
+			}
+
+			want := ReadmeSummary{Actions: 2, Workflows: 3, Starters: 2}
+			if summary != want {
+				t.Errorf("summary = %+v, want %+v", summary, want)
+			}
+		})
+	}
+}
+
+func TestGenerateReadmeCheck(t *testing.T) {
+	chdir(t, newTestRepo(t))
+
+	if err := os.WriteFile("README.md", []byte("outdated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator(Options{Check: true, Stdout: io.Discard})
+	if _, err := g.GenerateReadme(context.Background()); err == nil {
+		t.Fatal("GenerateReadme: expected an error for an outdated README")
+	}
+
+	if _, err := NewGenerator(Options{}).GenerateReadme(context.Background()); err != nil {
+		t.Fatalf("GenerateReadme: %s", err)
+	}
+	if _, err := g.GenerateReadme(context.Background()); err != nil {
+		t.Errorf("GenerateReadme: %s", err)
+	}
+}
+
+// newLargeTestRepo adds workflows workflows spread over 10 actions to the repository of newTestRepo
+func newLargeTestRepo(t testing.TB, workflows int) string {
+	t.Helper()
+
+	dir := newTestRepo(t)
+
+	wc := WorkflowConfig{}
+	if err := LoadConfigFile(&wc, filepath.Join(dir, DefaultConfigPath)); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	for i := 0; i < workflows; i++ {
+		workflowID := fmt.Sprintf("workflow-%03d", i)
+		actionPath := fmt.Sprintf("workflows/action-%d", i%10)
+		wc[workflowID] = Workflow{
+			Starter:        i%3 == 0,
+			Type:           "deployments",
+			WorkflowPath:   fmt.Sprintf("%s/%s.yml", actionPath, workflowID),
+			PropertiesPath: fmt.Sprintf("properties/%s.properties.json", workflowID),
+		}
+		files[actionPath+"/README.md"] = "# action\n"
+		files[wc[workflowID].WorkflowPath] = strings.Replace(testWorkflow, "Deploy to Cloud Run", "Workflow "+workflowID, 1)
+		files[wc[workflowID].PropertiesPath] = fmt.Sprintf(`{"name": "Workflow %s", "description": "Workflow %s.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Deployment"]}`, workflowID, workflowID)
+	}
+	writeTestFiles(t, dir, files)
+
+	if err := NewGenerator(Options{ConfigPath: filepath.Join(dir, DefaultConfigPath)}).WriteWorkflowConfig(wc); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestGenerateReadmeDeterministic(t *testing.T) {
+	chdir(t, newLargeTestRepo(t, 200))
+
+	var want []byte
+	for i := 0; i < 10; i++ {
+		if _, err := NewGenerator(Options{Workers: 8}).GenerateReadme(context.Background()); err != nil {
+			t.Fatalf("GenerateReadme: %s", err)
+		}
+
+		got, err := os.ReadFile("README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			want = got
+		} else if string(got) != string(want) {
+			t.Fatalf("run %d wrote README.md:\n%s\nwant the README of the first run:\n%s", i, got, want)
+		}
+	}
+}
+
+func BenchmarkGenerateReadme(b *testing.B) {
+	chdir(b, newLargeTestRepo(b, 200))
+
+	g := NewGenerator(Options{})
+	for i := 0; i < b.N; i++ {
+		if _, err := g.GenerateReadme(context.Background()); err != nil {
+			b.Fatalf("GenerateReadme: %s", err)
+		}
+	}
+}
+
+func TestEnsureActionReadme(t *testing.T) {
+	cases := []struct {
+		name     string
+		existing *string
+		want     string
+	}{
+		{
+			name: "missing",
+			want: "# deploy-cloudrun\n",
+		},
+		{
+			name:     "empty",
+			existing: stringPtr(""),
+			want:     "# deploy-cloudrun\n",
+		},
+		{
+			name:     "stub",
+			existing: stringPtr("# deploy-cloudrun examples\n"),
+			want:     "# deploy-cloudrun examples\n",
+		},
+		{
+			name:     "curated",
+			existing: stringPtr("# Cloud Run\n\nCurated examples of deploying to Cloud Run.\n"),
+			want:     "# Cloud Run\n\nCurated examples of deploying to Cloud Run.\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, newTestRepo(t))
+
+			readmePath := "workflows/deploy-cloudrun/README.md"
+			if err := os.Remove(readmePath); err != nil {
+				t.Fatal(err)
+			}
+			if tc.existing != nil {
+				writeTestFiles(t, ".", map[string]string{readmePath: *tc.existing})
+			}
+
+			g := NewGenerator(Options{})
+			var wc WorkflowConfig
+			if err := g.LoadWorkflowConfig(&wc); err != nil {
+				t.Fatal(err)
+			}
+
+			// ensuring twice is the same as ensuring once
+			for i := 0; i < 2; i++ {
+				if err := g.EnsureActionReadme(wc, "deploy-cloudrun", "workflows/deploy-cloudrun"); err != nil {
+					t.Fatalf("EnsureActionReadme: %s", err)
+				}
+			}
+
+			got, err := os.ReadFile(readmePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("%s = %q, want %q", readmePath, got, tc.want)
+			}
+		})
+	}
+}
+
+// stringPtr returns a pointer to s
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestReadmeActionCategories(t *testing.T) {
+	chdir(t, newTestRepo(t))
+
+	templateConfig, err := NewGenerator(Options{}).LoadReadmeTemplateConfig(false)
+	if err != nil {
+		t.Fatalf("LoadReadmeTemplateConfig: %s", err)
+	}
+
+	got := map[string][]string{}
+	for _, action := range templateConfig.Actions {
+		got[action.Name] = action.Categories
+	}
+
+	// cloudrun-docker and cloudrun-source both have the Cloud Run category
+	want := map[string][]string{
+		"deploy-appengine": {"Deployment"},
+		"deploy-cloudrun":  {"Cloud Run", "Deployment", "Serverless"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("action categories = %q, want %q", got, want)
+	}
+}
+
+func TestGenerateActionReadmesWorkers(t *testing.T) {
+	actionReadmes := map[int]map[string]string{}
+	for _, workers := range []int{1, 4} {
+		dir := newLargeTestRepo(t, 50)
+		writeTestFiles(t, dir, map[string]string{
+			"templates/action-README.tmpl.md": "# {{.Name}}\n{{range .Workflows}}- [{{.Name}}]({{.Path}}): {{.Description}}\n{{end}}",
+		})
+		chdir(t, dir)
+
+		if err := NewGenerator(Options{Workers: workers}).GenerateActionReadmes(context.Background()); err != nil {
+			t.Fatalf("GenerateActionReadmes with %d workers: %s", workers, err)
+		}
+
+		readmePaths, err := filepath.Glob("workflows/*/README.md")
+		if err != nil {
+			t.Fatal(err)
+		}
+		actionReadmes[workers] = map[string]string{}
+		for _, readmePath := range readmePaths {
+			contents, err := os.ReadFile(readmePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actionReadmes[workers][filepath.ToSlash(readmePath)] = string(contents)
+		}
+	}
+
+	if got := len(actionReadmes[1]); got != 12 {
+		t.Fatalf("GenerateActionReadmes wrote %d action READMEs, want 12", got)
+	}
+	if !reflect.DeepEqual(actionReadmes[4], actionReadmes[1]) {
+		t.Errorf("GenerateActionReadmes with 4 workers wrote:\n%q\nwant the action READMEs of 1 worker:\n%q", actionReadmes[4], actionReadmes[1])
+	}
+}
+
+func TestReadmeBrokenLinks(t *testing.T) {
+	cases := []struct {
+		name       string
+		files      map[string]string
+		checkLinks bool
+		wantErr    string
+		wantLogs   string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "missing_workflow",
+			files: map[string]string{
+				"workflows/deploy-cloudrun/cloudrun-source.yml": "",
+			},
+			wantErr:  "found 1 broken link(s)",
+			wantLogs: "error: validation failed for generate readme: README.md links to workflows/deploy-cloudrun/cloudrun-source.yml, which does not exist\n",
+		},
+		{
+			name: "unchecked_action_readme_link",
+			files: map[string]string{
+				"workflows/deploy-cloudrun/README.md": "# deploy-cloudrun\n\nSee [the docs](docs/setup.md).\n",
+			},
+		},
+		{
+			name: "action_readme_link",
+			files: map[string]string{
+				"workflows/deploy-cloudrun/README.md": "# deploy-cloudrun\n\nSee [the docs](docs/setup.md), [Docker](cloudrun-docker.yml#L1) and [Cloud Run](https://cloud.google.com/run).\n",
+			},
+			checkLinks: true,
+			wantErr:    "found 1 broken link(s)",
+			wantLogs:   "error: validation failed for generate readme: workflows/deploy-cloudrun/README.md links to docs/setup.md, which does not exist\n",
+		},
+		{
+			name: "aggregated",
+			files: map[string]string{
+				"workflows/deploy-cloudrun/README.md":           "# deploy-cloudrun\n\nSee [the docs](docs/setup.md).\n",
+				"workflows/deploy-cloudrun/cloudrun-source.yml": "",
+			},
+			checkLinks: true,
+			wantErr:    "found 2 broken link(s)",
+			wantLogs: "error: validation failed for generate readme: README.md links to workflows/deploy-cloudrun/cloudrun-source.yml, which does not exist\n" +
+				"error: validation failed for generate readme: workflows/deploy-cloudrun/README.md links to docs/setup.md, which does not exist\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t)
+			chdir(t, dir)
+			for name, contents := range tc.files {
+				if contents == "" {
+					if err := os.Remove(name); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				writeTestFiles(t, dir, map[string]string{name: contents})
+			}
+
+			var logs bytes.Buffer
+			g := NewGenerator(Options{CheckLinks: tc.checkLinks, Logger: NewLogger(&logs, LevelInfo)})
+			_, err := g.LoadReadmeTemplateConfig(false)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("LoadReadmeTemplateConfig: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("LoadReadmeTemplateConfig error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logs.String(); got != tc.wantLogs {
+				t.Errorf("LoadReadmeTemplateConfig logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"sort"
+	"strings"
+)
+
+// SortedWorkflowIDs returns the workflow IDs of a workflow config sorted by name
+func SortedWorkflowIDs(wc WorkflowConfig) []string {
+	workflowIDs := make([]string, 0, len(wc))
+	for id := range wc {
+		workflowIDs = append(workflowIDs, id)
+	}
+	sort.Strings(workflowIDs)
+
+	return workflowIDs
+}
+
+// SortedKeys returns the keys of a count map sorted by name
+func SortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SortedTypes returns the valid workflow types sorted by name
+func SortedTypes() []string {
+	types := make([]string, 0, len(TypeCategories))
+	for workflowType := range TypeCategories {
+		types = append(types, workflowType)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// SortedActions sorts a list of readmeActions with the actions in order first,
+// followed by the other actions sorted by name
+func SortedActions(actions map[string]ReadmeAction, order []string) []ReadmeAction {
+	priority := make(map[string]int, len(order))
+	for i, name := range order {
+		priority[name] = i
+	}
+
+	actionNames := make([]string, 0, len(actions))
+	for name := range actions {
+		actionNames = append(actionNames, name)
+	}
+	sort.Slice(actionNames, func(i, j int) bool {
+		iPriority, iOK := priority[actionNames[i]]
+		jPriority, jOK := priority[actionNames[j]]
+		if iOK != jOK {
+			return iOK
+		}
+		if iOK {
+			return iPriority < jPriority
+		}
+		return actionNames[i] < actionNames[j]
+	})
+
+	readmeActionData := make([]ReadmeAction, 0, len(actions))
+	for _, actionName := range actionNames {
+		readmeActionData = append(readmeActionData, actions[actionName])
+	}
+
+	return readmeActionData
+}
+
+// SortReadmeWorkflows sorts workflows by name, case-insensitively, optionally listing starter workflows first
+func SortReadmeWorkflows(workflows []ReadmeWorkflow, starterFirst bool) {
+	sort.SliceStable(workflows, func(i, j int) bool {
+		if starterFirst && workflows[i].Starter != workflows[j].Starter {
+			return workflows[i].Starter
+		}
+
+		iName, jName := strings.ToLower(workflows[i].Name), strings.ToLower(workflows[j].Name)
+		if iName != jName {
+			return iName < jName
+		}
+		return workflows[i].RelativeName < workflows[j].RelativeName
+	})
+}
+
+// SortCategories returns a copy of the categories sorted ignoring case
+func SortCategories(categories []string) []string {
+	sorted := append([]string{}, categories...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i]) < strings.ToLower(sorted[j])
+	})
+	return sorted
+}
+
+// GroupReadmeWorkflows groups workflows by their intermediate path, with the
+// workflows directly under the action first followed by the sorted groups
+func GroupReadmeWorkflows(workflows []ReadmeWorkflow) []ReadmeGroup {
+	groupWorkflows := map[string][]ReadmeWorkflow{}
+	for _, workflow := range workflows {
+		groupWorkflows[workflow.Group] = append(groupWorkflows[workflow.Group], workflow)
+	}
+
+	groupNames := make([]string, 0, len(groupWorkflows))
+	for name := range groupWorkflows {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+
+	groups := make([]ReadmeGroup, 0, len(groupNames))
+	for _, name := range groupNames {
+		groups = append(groups, ReadmeGroup{
+			Name:      name,
+			Workflows: groupWorkflows[name],
+		})
+	}
+
+	return groups
+}
+
+// ActionCategories returns the sorted, deduplicated union of the categories of an action's workflows
+func ActionCategories(workflows []ReadmeWorkflow) []string {
+	seen := map[string]bool{}
+	categories := make([]string, 0)
+	for _, workflow := range workflows {
+		for _, category := range workflow.Categories {
+			if !seen[category] {
+				seen[category] = true
+				categories = append(categories, category)
+			}
+		}
+	}
+	sort.Strings(categories)
+
+	return categories
+}
+
+// sortedCollisions returns the groups of more than one workflow ID, sorted by their key
+func sortedCollisions(groups map[string][]string) [][]string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	collisions := make([][]string, 0)
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			collisions = append(collisions, groups[key])
+		}
+	}
+
+	return collisions
+}
@@ -0,0 +1,141 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortedActions(t *testing.T) {
+	actions := map[string]ReadmeAction{
+		"auth":            {Name: "auth"},
+		"deploy-cloudrun": {Name: "deploy-cloudrun"},
+		"setup-gcloud":    {Name: "setup-gcloud"},
+	}
+
+	cases := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{
+			name: "by_name",
+			want: []string{"auth", "deploy-cloudrun", "setup-gcloud"},
+		},
+		{
+			name:  "order_first",
+			order: []string{"setup-gcloud"},
+			want:  []string{"setup-gcloud", "auth", "deploy-cloudrun"},
+		},
+		{
+			name:  "unknown_order_ignored",
+			order: []string{"missing", "deploy-cloudrun", "auth"},
+			want:  []string{"deploy-cloudrun", "auth", "setup-gcloud"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, action := range SortedActions(actions, tc.order) {
+				got = append(got, action.Name)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("SortedActions = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSortReadmeWorkflows(t *testing.T) {
+	cases := []struct {
+		name         string
+		starterFirst bool
+		want         []string
+	}{
+		{
+			name: "by_name_ignoring_case",
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name:         "starter_first",
+			starterFirst: true,
+			want:         []string{"c", "a", "b"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			workflows := []ReadmeWorkflow{
+				{Name: "Deploy", RelativeName: "b"},
+				{Name: "deploy", RelativeName: "a"},
+				{Name: "Publish", RelativeName: "c", Starter: true},
+			}
+			SortReadmeWorkflows(workflows, tc.starterFirst)
+
+			got := make([]string, 0, len(workflows))
+			for _, workflow := range workflows {
+				got = append(got, workflow.RelativeName)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("SortReadmeWorkflows = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGroupReadmeWorkflows(t *testing.T) {
+	workflows := []ReadmeWorkflow{
+		{RelativeName: "gke/deploy", Group: "gke"},
+		{RelativeName: "deploy"},
+		{RelativeName: "cloudrun/deploy", Group: "cloudrun"},
+	}
+
+	got := make([]string, 0)
+	for _, group := range GroupReadmeWorkflows(workflows) {
+		got = append(got, group.Name)
+	}
+
+	want := []string{"", "cloudrun", "gke"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupReadmeWorkflows = %v, want %v", got, want)
+	}
+}
+
+func TestSortCategories(t *testing.T) {
+	got := SortCategories([]string{"Serverless", "declarative", "Cloud Run"})
+
+	want := []string{"Cloud Run", "declarative", "Serverless"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortCategories = %v, want %v", got, want)
+	}
+}
+
+func TestActionCategories(t *testing.T) {
+	got := ActionCategories([]ReadmeWorkflow{
+		{Categories: []string{"Deployment", "Cloud Run"}},
+		{Categories: []string{"Cloud Run", "Containers"}},
+	})
+
+	want := []string{"Cloud Run", "Containers", "Deployment"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ActionCategories = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"unicode"
+)
+
+var (
+	// templateFuncs are the functions available to all templates
+	templateFuncs = template.FuncMap{
+		"anchor":   GithubAnchor,
+		"humanize": Humanize,
+		"title":    TitleCase,
+		"upper":    strings.ToUpper,
+	}
+
+	// humanizeWords are the words Humanize replaces with their product spelling
+	humanizeWords = map[string]string{
+		"cloudrun": "Cloud Run",
+		"gke":      "GKE",
+		"krm":      "KRM",
+	}
+)
+
+// RenderTemplate renders a go template to a writer
+func RenderTemplate(templatePath string, out io.Writer, templateConfig interface{}) error {
+	tmpl, err := template.New(path.Base(templatePath)).Funcs(templateFuncs).ParseFiles(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	err = tmpl.Execute(out, templateConfig)
+	if err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// RenderDataTemplateFile renders a go template of a data file, such as the properties template, to a temp
+// file and renames it over outputPath. Unlike RenderTemplate, values are not HTML-escaped, and the template
+// quotes them with the json function, which also makes them valid YAML strings.
+func RenderDataTemplateFile(templatePath string, outputPath string, templateConfig interface{}) error {
+	funcs := texttemplate.FuncMap{"json": jsonValue}
+	for name, fn := range templateFuncs {
+		funcs[name] = fn
+	}
+
+	tmpl, err := texttemplate.New(path.Base(templatePath)).Funcs(funcs).ParseFiles(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, templateConfig); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return WriteFileAtomic(outputPath, rendered.Bytes())
+}
+
+// jsonValue marshals a template value to JSON without escaping HTML characters, e.g. "O'Brien & Co"
+func jsonValue(value interface{}) (string, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// RenderTemplateFile renders a go template to a temp file and renames it over outputPath,
+// so outputPath is never left partially written
+func RenderTemplateFile(templatePath string, outputPath string, templateConfig interface{}) error {
+	var rendered bytes.Buffer
+	if err := RenderTemplate(templatePath, &rendered, templateConfig); err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(outputPath, rendered.Bytes())
+}
+
+// renderTemplateFileIfChanged renders a template to outputPath only when the SHA-256 hash of the rendered
+// content differs from the hash of the file on disk, reporting whether the file was written
+func renderTemplateFileIfChanged(templatePath string, outputPath string, templateConfig interface{}) (bool, error) {
+	var rendered bytes.Buffer
+	if err := RenderTemplate(templatePath, &rendered, templateConfig); err != nil {
+		return false, err
+	}
+
+	existing, err := os.ReadFile(outputPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", outputPath, err)
+	}
+
+	if err == nil && sha256.Sum256(existing) == sha256.Sum256(rendered.Bytes()) {
+		return false, nil
+	}
+
+	return true, WriteFileAtomic(outputPath, rendered.Bytes())
+}
+
+// MkdirParent creates the missing parent directories of filePath, so outputs can be written into a fresh tree
+func MkdirParent(filePath string) error {
+	if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// WriteFileAtomic writes content to a temp file next to outputPath and renames it over outputPath
+func WriteFileAtomic(outputPath string, content []byte) error {
+	if err := MkdirParent(outputPath); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(path.Dir(outputPath), fmt.Sprintf(".%s.*.tmp", path.Base(outputPath)))
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if _, err := file.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.Name(), err)
+	}
+
+	if err := file.Chmod(0o644); err != nil {
+		return fmt.Errorf("failed to set permissions of %s: %w", file.Name(), err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.Name(), err)
+	}
+
+	if err := os.Rename(file.Name(), outputPath); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", file.Name(), outputPath, err)
+	}
+
+	return nil
+}
+
+// Humanize converts a hyphenated ID to spaced title case, e.g. deploy-cloudrun becomes Deploy Cloud Run
+func Humanize(id string) string {
+	words := strings.FieldsFunc(id, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+
+	for i, word := range words {
+		if humanized, ok := humanizeWords[strings.ToLower(word)]; ok {
+			words[i] = humanized
+		} else {
+			words[i] = TitleCase(word)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// TitleCase upper cases the first letter of each space separated word
+func TitleCase(s string) string {
+	words := strings.Split(s, " ")
+	for i, word := range words {
+		if word != "" {
+			runes := []rune(word)
+			words[i] = string(unicode.ToUpper(runes[0])) + string(runes[1:])
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// GithubAnchor converts a heading to the anchor GitHub generates for it: lowercase,
+// spaces replaced with hyphens and punctuation other than hyphens and underscores removed
+func GithubAnchor(heading string) string {
+	var anchor strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			anchor.WriteRune(r)
+		case r == ' ':
+			anchor.WriteRune('-')
+		}
+	}
+	return anchor.String()
+}
+
+// LineDiff returns the lines removed from before, prefixed with "- ", and the lines
+// added in after, prefixed with "+ ", in order using the longest common subsequence
+func LineDiff(before []string, after []string) []string {
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diff := make([]string, 0)
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			i++
+			j++
+		case j >= len(after) || (i < len(before) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, fmt.Sprintf("- %s", before[i]))
+			i++
+		default:
+			diff = append(diff, fmt.Sprintf("+ %s", after[j]))
+			j++
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,359 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		config   interface{}
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "fields",
+			template: "{{.Name}} has {{len .Workflows}} workflow(s)",
+			config:   ReadmeAction{Name: "auth", Workflows: []ReadmeWorkflow{{}, {}}},
+			want:     "auth has 2 workflow(s)",
+		},
+		{
+			name:     "funcs",
+			template: `{{humanize "deploy-cloudrun"}} {{anchor "Deploy to GKE!"}} {{title "cloud run"}} {{upper "ci"}}`,
+			want:     "Deploy Cloud Run deploy-to-gke Cloud Run CI",
+		},
+		{
+			name:     "invalid_template",
+			template: "{{.Name",
+			wantErr:  true,
+		},
+		{
+			name:     "missing_field",
+			template: "{{.Missing}}",
+			config:   ReadmeAction{},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			templatePath := filepath.Join(t.TempDir(), "README.tmpl.md")
+			writeTestFiles(t, filepath.Dir(templatePath), map[string]string{"README.tmpl.md": tc.template})
+
+			var out bytes.Buffer
+			err := RenderTemplate(templatePath, &out, tc.config)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("RenderTemplate: expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderTemplate: %s", err)
+			}
+			if got := out.String(); got != tc.want {
+				t.Errorf("RenderTemplate = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	cases := map[string]string{
+		"deploy-cloudrun":     "Deploy Cloud Run",
+		"get-gke-credentials": "Get GKE Credentials",
+		"krm_blueprint":       "KRM Blueprint",
+		"auth":                "Auth",
+	}
+
+	for id, want := range cases {
+		if got := Humanize(id); got != want {
+			t.Errorf("Humanize(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestGithubAnchor(t *testing.T) {
+	cases := map[string]string{
+		"deploy-cloudrun":          "deploy-cloudrun",
+		"Deploy to Cloud Run!":     "deploy-to-cloud-run",
+		" get_gke (credentials) ":  "get_gke-credentials",
+		"Google GitHub Actions v2": "google-github-actions-v2",
+		"Deploy Cloud Run v1.2":    "deploy-cloud-run-v12",
+	}
+
+	for heading, want := range cases {
+		if got := GithubAnchor(heading); got != want {
+			t.Errorf("GithubAnchor(%q) = %q, want %q", heading, got, want)
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := LineDiff([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+
+	want := []string{"- b", "+ d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LineDiff = %v, want %v", got, want)
+	}
+}
+
+func TestRenderActionReadmeTemplate(t *testing.T) {
+	templatePath := filepath.Join("..", "..", DefaultTemplateDir, "action-README.tmpl.md")
+	docker := ActionReadmeWorkflow{
+		Name:            "Deploy with Docker",
+		Description:     "Build and deploy a container.",
+		Starter:         true,
+		Aliases:         []string{"Cloud Run Docker"},
+		RequiredSecrets: []string{"WIF_PROVIDER"},
+		Variables:       []PropertiesVariable{{Name: "PROJECT_ID", Description: "Google Cloud project ID", Required: true}},
+		Path:            "cloudrun-docker.yml",
+		URL:             "cloudrun-docker.yml",
+		Preview:         "name: Deploy with Docker",
+	}
+	source := ActionReadmeWorkflow{
+		Name:                "Deploy from source",
+		Description:         "Deploy source code.",
+		RequiredPermissions: []string{"id-token: write"},
+		Deprecated:          true,
+		DeprecationNote:     "Use Docker.",
+		Path:                "source/cloudrun-source.yml",
+		URL:                 "https://github.com/org/repo/blob/main/workflows/deploy-cloudrun/source/cloudrun-source.yml",
+	}
+	config := ActionReadmeTemplateConfig{
+		Name:       "deploy-cloudrun",
+		Categories: []string{"Cloud Run", "Deployment"},
+		Workflows:  []ActionReadmeWorkflow{docker, source},
+		Groups: []ActionReadmeGroup{
+			{Workflows: []ActionReadmeWorkflow{docker}},
+			{Name: "source", Workflows: []ActionReadmeWorkflow{source}},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := RenderTemplate(templatePath, &out, config); err != nil {
+		t.Fatalf("RenderTemplate: %s", err)
+	}
+
+	// the variables and previews of a group follow its table, like in the index README
+	want := `# deploy-cloudrun examples
+
+` + "`Cloud Run` `Deployment`" + `
+
+| Name | Starter | Description |
+| ---- | ------- | ----------- |
+| [Deploy with Docker](cloudrun-docker.yml) | ✅ | Build and deploy a container. Also known as: _Cloud Run Docker_. Required secrets: ` + "`WIF_PROVIDER`" + `. |
+
+**Deploy with Docker** variables:
+
+| Variable | Required | Description |
+| -------- | -------- | ----------- |
+| ` + "`PROJECT_ID`" + ` | ✅ | Google Cloud project ID |
+
+<details>
+<summary>Deploy with Docker</summary>
+
+<pre><code>name: Deploy with Docker</code></pre>
+
+</details>
+
+## source
+
+| Name | Starter | Description |
+| ---- | ------- | ----------- |
+| ~~[Deploy from source](https://github.com/org/repo/blob/main/workflows/deploy-cloudrun/source/cloudrun-source.yml)~~ (deprecated) |  | Deploy source code. Required permissions: ` + "`id-token: write`" + `. **Deprecated:** Use Docker. |
+`
+	if got := out.String(); got != want {
+		t.Errorf("RenderTemplate = %q, want %q", got, want)
+	}
+
+	// RenderTemplateFile writes the same output
+	outputPath := filepath.Join(t.TempDir(), "README.md")
+	if err := RenderTemplateFile(templatePath, outputPath, config); err != nil {
+		t.Fatalf("RenderTemplateFile: %s", err)
+	}
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("RenderTemplateFile wrote %q, want %q", got, want)
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "anchor",
+			template: `{{anchor "Deploy to GKE!"}}`,
+			want:     "deploy-to-gke",
+		},
+		{
+			name:     "humanize",
+			template: `{{humanize "deploy-cloudrun"}}`,
+			want:     "Deploy Cloud Run",
+		},
+		{
+			name:     "title",
+			template: `{{title "cloud run"}}`,
+			want:     "Cloud Run",
+		},
+		{
+			name:     "upper",
+			template: `{{upper "ci"}}`,
+			want:     "CI",
+		},
+	}
+
+	for name := range templateFuncs {
+		found := false
+		for _, tc := range cases {
+			found = found || tc.name == name
+		}
+		if !found {
+			t.Errorf("template function %s is not tested", name)
+		}
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{"README.tmpl.md": tc.template})
+
+			var out bytes.Buffer
+			if err := RenderTemplate(filepath.Join(dir, "README.tmpl.md"), &out, nil); err != nil {
+				t.Fatalf("RenderTemplate: %s", err)
+			}
+			if got := out.String(); got != tc.want {
+				t.Errorf("RenderTemplate = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplateFile(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "rendered",
+			template: "# {{.Name}}\n",
+			want:     "# auth\n",
+		},
+		{
+			name:     "execution_error",
+			template: "# {{.Name}}\n{{.Missing}}\n",
+			want:     "# original\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFiles(t, dir, map[string]string{
+				"README.tmpl.md": tc.template,
+				"README.md":      "# original\n",
+			})
+
+			err := RenderTemplateFile(filepath.Join(dir, "README.tmpl.md"), filepath.Join(dir, "README.md"), ReadmeAction{Name: "auth"})
+			if tc.wantErr && err == nil {
+				t.Fatal("RenderTemplateFile: expected an error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("RenderTemplateFile: %s", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "README.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("RenderTemplateFile left README.md with %q, want %q", got, tc.want)
+			}
+
+			// the temp file is removed whether or not the template rendered
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			if want := []string{"README.md", "README.tmpl.md"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("RenderTemplateFile left %q, want %q", names, want)
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomicParentDirs(t *testing.T) {
+	// neither the action nor the nested group directory exist yet
+	outputPath := filepath.Join(t.TempDir(), "workflows", "deploy-cloudrun", "go", "README.md")
+
+	if err := WriteFileAtomic(outputPath, []byte("# Deploy Cloud Run\n")); err != nil {
+		t.Fatalf("WriteFileAtomic: %s", err)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Deploy Cloud Run\n"; string(got) != want {
+		t.Errorf("WriteFileAtomic wrote %q, want %q", got, want)
+	}
+}
+
+func TestRenderDataTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"workflow.properties.tmpl.json": "{\"creator\": {{ json .Creator }}, \"categories\": [{{ range $i, $c := .Categories }}{{ if $i }}, {{ end }}{{ json $c }}{{ end }}]}\n",
+	})
+
+	config := PropertiesConfig{Creator: `O'Brien & "Co" \ Ltd`, Categories: []string{"R&D <Labs>"}}
+	outputPath := filepath.Join(dir, "properties.json")
+	if err := RenderDataTemplateFile(filepath.Join(dir, "workflow.properties.tmpl.json"), outputPath, config); err != nil {
+		t.Fatalf("RenderDataTemplateFile: %s", err)
+	}
+
+	got, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\"creator\": \"O'Brien & \\\"Co\\\" \\\\ Ltd\", \"categories\": [\"R&D <Labs>\"]}\n"; string(got) != want {
+		t.Errorf("RenderDataTemplateFile wrote %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// tomlComments are the comments of a TOML workflow config, kept when the config is rewritten
+type tomlComments struct {
+	// header are the comments at the top of the file, separated from the first table by a blank line
+	header []string
+	// tables are the comments above the header of each table, by workflow ID
+	tables map[string][]string
+	// keys are the comments above each key within the body of each table, by workflow ID and key
+	keys map[string]map[string][]string
+	// inline are the comments at the end of the header, by workflow ID, and key lines, by workflow ID and key
+	inline map[string]map[string]string
+	// footer are the comments after the last table
+	footer []string
+}
+
+// decodeTOML parses a TOML document into nested maps
+func decodeTOML(content []byte) (map[string]interface{}, error) {
+	doc := make(map[string]interface{})
+	if _, err := toml.Decode(string(content), &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// marshalTOMLWorkflowConfig returns the workflow config as TOML, with a table for each workflow,
+// keeping the comments of existing, the TOML config being rewritten
+func marshalTOMLWorkflowConfig(wc WorkflowConfig, existing []byte) ([]byte, error) {
+	comments := parseTOMLComments(existing)
+
+	var b bytes.Buffer
+	writeTOMLComments(&b, comments.header)
+	if len(comments.header) > 0 && len(wc) > 0 {
+		b.WriteString("\n")
+	}
+
+	for i, workflowID := range SortedWorkflowIDs(wc) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		var table bytes.Buffer
+		enc := toml.NewEncoder(&table)
+		enc.Indent = ""
+		if err := enc.Encode(map[string]Workflow{workflowID: wc[workflowID]}); err != nil {
+			return nil, fmt.Errorf("failed to marshal workflow %s: %w", workflowID, err)
+		}
+
+		writeTOMLComments(&b, comments.tables[workflowID])
+		for _, line := range strings.Split(strings.TrimSpace(table.String()), "\n") {
+			// the inline comment of the header is stored under the empty key
+			key := ""
+			if !strings.HasPrefix(line, "[") {
+				key = tomlKey(line)
+				writeTOMLComments(&b, comments.keys[workflowID][key])
+			}
+			if comment, ok := comments.inline[workflowID][key]; ok {
+				line += " " + comment
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	if len(comments.footer) > 0 && b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	writeTOMLComments(&b, comments.footer)
+
+	return b.Bytes(), nil
+}
+
+// parseTOMLComments collects the comments of a TOML workflow config by the table or key they
+// precede or end, comments that are not followed by a table are kept at the end of the file
+func parseTOMLComments(content []byte) tomlComments {
+	comments := tomlComments{
+		tables: map[string][]string{},
+		keys:   map[string]map[string][]string{},
+		inline: map[string]map[string]string{},
+	}
+
+	table := ""
+	pending := make([]string, 0)
+	blankAfterPending := false
+	for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			blankAfterPending = len(pending) > 0
+		case strings.HasPrefix(trimmed, "#"):
+			if blankAfterPending && table == "" && len(comments.header) == 0 {
+				// a comment block separated by a blank line before the first table is the file header
+				comments.header, pending = pending, make([]string, 0)
+			}
+			blankAfterPending = false
+			pending = append(pending, trimmed)
+		case strings.HasPrefix(trimmed, "["):
+			if table == "" && blankAfterPending && len(comments.header) == 0 {
+				comments.header, pending = pending, make([]string, 0)
+			}
+			code, comment := splitTOMLComment(trimmed)
+			table = tomlKey(code)
+			comments.tables[table] = append(comments.tables[table], pending...)
+			comments.keys[table] = map[string][]string{}
+			comments.inline[table] = map[string]string{}
+			if comment != "" {
+				comments.inline[table][""] = comment
+			}
+			pending, blankAfterPending = make([]string, 0), false
+		default:
+			// comments between the keys of a table belong to the key they precede
+			if table != "" {
+				code, comment := splitTOMLComment(trimmed)
+				key := tomlKey(code)
+				comments.keys[table][key] = append(comments.keys[table][key], pending...)
+				if comment != "" {
+					comments.inline[table][key] = comment
+				}
+			}
+			pending, blankAfterPending = make([]string, 0), false
+		}
+	}
+	if table == "" && len(comments.header) == 0 {
+		comments.header = pending
+	} else {
+		comments.footer = pending
+	}
+
+	return comments
+}
+
+// tomlKey returns the key of a TOML table header or key/value line, e.g. cloudrun-source
+// for [cloudrun-source] and starter for starter = true
+func tomlKey(line string) string {
+	var doc map[string]interface{}
+	md, err := toml.Decode(line, &doc)
+	if err != nil || len(md.Keys()) == 0 || len(md.Keys()[0]) != 1 {
+		return line
+	}
+
+	return md.Keys()[0][0]
+}
+
+// splitTOMLComment splits a TOML line into its code and its comment, ignoring the # of strings
+func splitTOMLComment(line string) (string, string) {
+	for i, r := range line {
+		if r != '#' {
+			continue
+		}
+
+		// the code before a comment is valid TOML on its own, unlike the code before a # in a string
+		code := strings.TrimSpace(line[:i])
+		var doc map[string]interface{}
+		if _, err := toml.Decode(code, &doc); err == nil {
+			return code, line[i:]
+		}
+	}
+
+	return line, ""
+}
+
+// writeTOMLComments writes each comment on its own line
+func writeTOMLComments(b *bytes.Buffer, comments []string) {
+	for _, comment := range comments {
+		b.WriteString(comment + "\n")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalTOMLWorkflowConfig(t *testing.T) {
+	wc := WorkflowConfig{
+		"auth-simple":     {Starter: true, Type: "ci", WorkflowPath: "workflows/auth/auth-simple.yml", PropertiesPath: "properties/auth-simple.properties.json"},
+		"cloudrun-source": {Type: "deployments", WorkflowPath: "workflows/deploy-cloudrun/cloudrun-source.yml", PropertiesPath: "properties/cloudrun-source.properties.json"},
+	}
+
+	cases := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{
+			name: "no_existing_config",
+			want: `[auth-simple]
+starter = true
+type = "ci"
+workflowPath = "workflows/auth/auth-simple.yml"
+propertiesPath = "properties/auth-simple.properties.json"
+
+[cloudrun-source]
+starter = false
+type = "deployments"
+workflowPath = "workflows/deploy-cloudrun/cloudrun-source.yml"
+propertiesPath = "properties/cloudrun-source.properties.json"
+`,
+		},
+		{
+			name: "comments_kept",
+			existing: `# Example workflows
+
+# Deploy to Cloud Run from source
+[cloudrun-source] # no starter yet
+starter = false
+# checked by validate
+type = "deployments" # "#" is not a comment in a string
+workflowPath = "workflows/deploy-cloudrun/cloudrun-source.yml"
+propertiesPath = "properties/cloudrun-source.properties.json"
+
+# end of the workflows
+`,
+			want: `# Example workflows
+
+[auth-simple]
+starter = true
+type = "ci"
+workflowPath = "workflows/auth/auth-simple.yml"
+propertiesPath = "properties/auth-simple.properties.json"
+
+# Deploy to Cloud Run from source
+[cloudrun-source] # no starter yet
+starter = false
+# checked by validate
+type = "deployments" # "#" is not a comment in a string
+workflowPath = "workflows/deploy-cloudrun/cloudrun-source.yml"
+propertiesPath = "properties/cloudrun-source.properties.json"
+
+# end of the workflows
+`,
+		},
+		{
+			name: "comments_of_removed_workflow_dropped",
+			existing: `# Removed workflow
+[removed]
+# removed key
+starter = true
+`,
+			want: `[auth-simple]
+starter = true
+type = "ci"
+workflowPath = "workflows/auth/auth-simple.yml"
+propertiesPath = "properties/auth-simple.properties.json"
+
+[cloudrun-source]
+starter = false
+type = "deployments"
+workflowPath = "workflows/deploy-cloudrun/cloudrun-source.yml"
+propertiesPath = "properties/cloudrun-source.properties.json"
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := marshalTOMLWorkflowConfig(wc, []byte(tc.existing))
+			if err != nil {
+				t.Fatalf("marshalTOMLWorkflowConfig: %s", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("marshalTOMLWorkflowConfig = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileTOML(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"workflow.config.toml": `# Deploy to Cloud Run from source
+["cloudrun-source"]
+starter = true
+type = "deployments"
+workflowPath = "workflows/deploy-cloudrun/cloudrun-source.yml" # moved from workflows/cloudrun
+propertiesPath = 'properties/cloudrun-source.properties.json'
+`,
+		"invalid.toml": "[cloudrun-source\nstarter = true\n",
+	})
+
+	var wc WorkflowConfig
+	if err := LoadConfigFile(&wc, filepath.Join(dir, "workflow.config.toml")); err != nil {
+		t.Fatalf("LoadConfigFile: %s", err)
+	}
+
+	want := WorkflowConfig{
+		"cloudrun-source": {Starter: true, Type: "deployments", WorkflowPath: "workflows/deploy-cloudrun/cloudrun-source.yml", PropertiesPath: "properties/cloudrun-source.properties.json"},
+	}
+	if !reflect.DeepEqual(wc, want) {
+		t.Errorf("LoadConfigFile = %+v, want %+v", wc, want)
+	}
+
+	if err := LoadConfigFile(&wc, filepath.Join(dir, "invalid.toml")); err == nil {
+		t.Error("LoadConfigFile: expected an error for invalid TOML")
+	}
+}
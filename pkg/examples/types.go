@@ -0,0 +1,70 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// TypeCategories are the default properties categories for each starter workflow type,
+	// its keys are the types accepted by the starter workflows repository
+	TypeCategories = map[string][]string{
+		"automation":    {"Automation"},
+		"ci":            {"Continuous integration"},
+		"code-scanning": {"Code Scanning"},
+		"deployments":   {"Deployment"},
+	}
+
+	// typeDirPattern matches a subdirectory of a nested workflow type, e.g. go in ci/go
+	typeDirPattern = regexp.MustCompile(`^` + typeDirExpr + `$`)
+)
+
+// typeDirExpr is the regular expression of a subdirectory of a nested workflow type
+const typeDirExpr = `[a-z0-9-]+`
+
+// typePattern returns the regular expression of a valid workflow type, one of the keys of TypeCategories
+// optionally followed by subdirectories, as checked by IsValidType
+func typePattern() string {
+	types := SortedTypes()
+	for i, workflowType := range types {
+		types[i] = regexp.QuoteMeta(workflowType)
+	}
+	return fmt.Sprintf("^(%s)(/%s)*$", strings.Join(types, "|"), typeDirExpr)
+}
+
+// BaseType returns the starter workflow type of a workflow type, without the subdirectory of nested
+// types such as ci/go
+func BaseType(workflowType string) string {
+	return strings.SplitN(workflowType, "/", 2)[0]
+}
+
+// IsValidType checks that a workflow type is a valid starter workflow type, optionally followed by
+// the subdirectory it is released into, e.g. ci/go
+func IsValidType(workflowType string) bool {
+	if _, ok := TypeCategories[BaseType(workflowType)]; !ok {
+		return false
+	}
+
+	for _, dir := range strings.Split(workflowType, "/")[1:] {
+		if !typeDirPattern.MatchString(dir) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,1346 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// WorkflowStubContents is written to new workflow files until they are implemented
+	WorkflowStubContents string = "# TODO: Add meaningful workflow content here."
+	// minWorkflowBytes is the smallest workflow file considered to be implemented
+	minWorkflowBytes int = 64
+)
+
+var (
+	ErrInvalidWorkflowPath = errors.New("invalid workflow path")
+	ErrOrphanedFile        = errors.New("orphaned file")
+	ErrNoStarterWorkflow   = errors.New("no starter workflow")
+	ErrPropertiesFileName  = errors.New("properties file name does not match the workflow ID")
+	ErrUnsortedCategories  = errors.New("properties categories are not sorted")
+	ErrNoLicenseHeader     = errors.New("missing license header")
+)
+
+var (
+	// nameStopWords are the words ignored when comparing a workflow name to its workflow ID
+	nameStopWords = map[string]bool{
+		"a":    true,
+		"an":   true,
+		"and":  true,
+		"for":  true,
+		"from": true,
+		"in":   true,
+		"of":   true,
+		"the":  true,
+		"to":   true,
+		"with": true,
+	}
+
+	// validCategories are the categories accepted by the starter workflows repository,
+	// used when categories.json does not exist
+	validCategories = []string{
+		"Automation",
+		"Buildpacks",
+		"Cloud Deploy",
+		"Cloud Run",
+		"Code Scanning",
+		"Containers",
+		"Continuous integration",
+		"Deployment",
+		"Dockerfile",
+		"KRM",
+		"Kubernetes",
+		"Kustomize",
+		"Serverless",
+		"Service Definition",
+		"declarative",
+	}
+
+	// defaultLintRules are the workflow lint rules, used when lint-rules.json does not exist
+	defaultLintRules = []lintRule{
+		{
+			Name:    "image-tag-separator",
+			Pattern: `/\$\{\{\s*github\.sha\s*\}\}|/\$\{?GITHUB_SHA\b`,
+			Message: "image tag is separated with '/', use ':' before the commit SHA",
+		},
+	}
+
+	// defaultProductKeywords are the keywords properties names and descriptions use for the
+	// product of each action, used when product-keywords.json does not exist
+	defaultProductKeywords = map[string][]string{
+		"create-cloud-deploy-release": {"Cloud Deploy"},
+		"deploy-appengine":            {"App Engine"},
+		"deploy-cloud-functions":      {"Cloud Functions"},
+		"deploy-cloudrun":             {"Cloud Run"},
+		"get-gke-credentials":         {"GKE", "Kubernetes"},
+	}
+
+	// validTriggers are the workflow triggers allowed by CheckTriggers,
+	// used when triggers.json does not exist
+	validTriggers = []string{
+		"pull_request",
+		"push",
+		"release",
+		"schedule",
+		"workflow_dispatch",
+	}
+
+	// envVariablePattern matches a reference to an env variable in a workflow, e.g. env.PROJECT_ID
+	envVariablePattern = regexp.MustCompile(`\benv\.([A-Za-z_][A-Za-z0-9_]*)\b`)
+
+	// envVariableNamePattern matches a valid env variable name
+	envVariableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// blockScalarPattern matches a line starting a literal or folded block scalar, e.g. run: | or - >-
+	blockScalarPattern = regexp.MustCompile(`(?:^|[:-])\s*[|>][-+1-9]{0,2}\s*(?:#.*)?$`)
+)
+
+// Problem is a validation problem, with an empty WorkflowID for problems not specific to one workflow.
+// Warnings are reported without failing validation.
+type Problem struct {
+	WorkflowID string
+	Err        error
+	Warning    bool
+}
+
+func (p Problem) String() string {
+	if p.WorkflowID == "" {
+		return p.Err.Error()
+	}
+	return fmt.Sprintf("workflow %s: %s", p.WorkflowID, p.Err)
+}
+
+// ProblemCollector collects the validation problems of a workflow config by severity
+type ProblemCollector struct {
+	Problems []Problem
+
+	// strict records warnings as errors and failOnWarning counts warnings as failures
+	strict        bool
+	failOnWarning bool
+
+	// workflowIDs limits the recorded workflow problems to these workflows when not nil,
+	// problems of the whole config are always recorded
+	workflowIDs map[string]bool
+}
+
+// Error records a problem that fails validation
+func (c *ProblemCollector) Error(workflowID string, err error) {
+	if c.skips(workflowID) {
+		return
+	}
+	c.Problems = append(c.Problems, Problem{WorkflowID: workflowID, Err: err})
+}
+
+// Warn records a problem that is reported without failing validation, unless Strict is set
+func (c *ProblemCollector) Warn(workflowID string, err error) {
+	if c.skips(workflowID) {
+		return
+	}
+	c.Problems = append(c.Problems, Problem{WorkflowID: workflowID, Err: err, Warning: !c.strict})
+}
+
+// skips reports whether the problems of a workflow are not recorded
+func (c *ProblemCollector) skips(workflowID string) bool {
+	return workflowID != "" && c.workflowIDs != nil && !c.workflowIDs[workflowID]
+}
+
+// Failures returns the number of problems that fail validation, including warnings when FailOnWarning is set
+func (c *ProblemCollector) Failures() int {
+	count := 0
+	for _, problem := range c.Problems {
+		if !problem.Warning || c.failOnWarning {
+			count++
+		}
+	}
+
+	return count
+}
+
+// LocatedError is a problem in a file, at a line of it when Line is set, reported with its location by validate --json
+type LocatedError struct {
+	Path string
+	Line int
+	Err  error
+}
+
+func (e *LocatedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LocatedError) Unwrap() error {
+	return e.Err
+}
+
+// lintRule is a pattern that should not appear in workflow values, loaded from lint-rules.json
+type lintRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	Message string `json:"message"`
+}
+
+// compiledLintRule is a lintRule with its compiled pattern
+type compiledLintRule struct {
+	lintRule
+	pattern *regexp.Regexp
+}
+
+// actionlintFinding is a problem reported by actionlint with -format '{{json .}}'
+type actionlintFinding struct {
+	Message string `json:"message"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Kind    string `json:"kind"`
+}
+
+// Validate runs every validation of the workflow config, returning the config and the problems found
+func (g *Generator) Validate() (WorkflowConfig, *ProblemCollector, error) {
+	var wfConfig WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wfConfig); err != nil {
+		return nil, nil, fmt.Errorf("failed to load workflow config %s: %w", g.ConfigPath, err)
+	}
+
+	categories, err := g.LoadValidCategories()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load valid categories: %w", err)
+	}
+
+	var triggers map[string]bool
+	if g.CheckTriggers {
+		if triggers, err = g.loadValidTriggers(); err != nil {
+			return nil, nil, fmt.Errorf("failed to load valid triggers: %w", err)
+		}
+	}
+
+	lintRules, err := g.loadLintRules()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load lint rules: %w", err)
+	}
+
+	productKeywords, err := g.loadProductKeywords()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load product keywords: %w", err)
+	}
+
+	licenseHeader, err := g.loadLicenseHeader()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load license header: %w", err)
+	}
+
+	actionlintPath := ""
+	if g.Actionlint {
+		if actionlintPath, err = exec.LookPath("actionlint"); err != nil {
+			g.Logger.Warnf("skipping actionlint, it was not found on PATH: %s", err)
+		}
+	}
+
+	collector := &ProblemCollector{strict: g.Strict, failOnWarning: g.FailOnWarning}
+	if g.Since != "" {
+		collector.workflowIDs = g.getChangedWorkflowIDs(wfConfig, g.Since)
+	}
+	workflowPaths := map[string]string{}
+	workflowNames := map[string][]string{}
+	workflowContents := map[string][]string{}
+	actionWorkflows := map[string][]Workflow{}
+
+	for _, workflowID := range SortedWorkflowIDs(wfConfig) {
+		workflow := wfConfig[workflowID]
+
+		if existingID, ok := workflowPaths[workflow.WorkflowPath]; ok {
+			collector.Error(workflowID, fmt.Errorf("workflow path %s is already used by workflow %s", workflow.WorkflowPath, existingID))
+		} else {
+			workflowPaths[workflow.WorkflowPath] = workflowID
+		}
+
+		if errs := g.validateConfigPaths(workflow); len(errs) > 0 {
+			for _, err := range errs {
+				collector.Error(workflowID, err)
+			}
+			continue
+		}
+
+		if err := ValidatePropertiesFileName(workflowID, workflow); err != nil {
+			collector.Error(workflowID, err)
+		}
+
+		actionPath, err := g.ActionPathOf(workflow.WorkflowPath)
+		if err != nil {
+			collector.Error(workflowID, err)
+			continue
+		}
+
+		actionWorkflows[path.Base(actionPath)] = append(actionWorkflows[path.Base(actionPath)], workflow)
+
+		actionReadMePath := path.Join(actionPath, "README.md")
+		for _, err := range g.validateGenerateReadme(workflow, ReadmeAction{ReadMePath: actionReadMePath}, triggers) {
+			collector.Error(workflowID, err)
+		}
+
+		for _, err := range lintWorkflow(workflowID, workflow.WorkflowPath, lintRules) {
+			collector.Warn(workflowID, err)
+		}
+
+		// a missing workflow file has already been reported
+		if _, err := os.Stat(workflow.WorkflowPath); err == nil && actionlintPath != "" {
+			g.Logger.Debugf("running actionlint on %s", workflow.WorkflowPath)
+			for _, err := range runActionlint(actionlintPath, workflow.WorkflowPath) {
+				collector.Error(workflowID, err)
+			}
+		}
+
+		if licenseHeader != nil {
+			if err := g.validateLicenseHeader(workflow.WorkflowPath, licenseHeader); err != nil {
+				collector.Error(workflowID, err)
+			}
+		}
+
+		// a missing or unreadable workflow file has already been reported
+		if contentHash, err := workflowContentHash(workflow.WorkflowPath); err == nil {
+			workflowContents[contentHash] = append(workflowContents[contentHash], workflowID)
+		}
+
+		if _, err := os.Stat(workflow.PropertiesPath); err != nil {
+			// the missing properties file has already been reported
+			continue
+		}
+
+		var properties PropertiesConfig
+		if err := LoadConfigFile(&properties, workflow.PropertiesPath); err != nil {
+			collector.Error(workflowID, fmt.Errorf("failed to load properties file %s: %w", workflow.PropertiesPath, err))
+			continue
+		}
+		g.Logger.Debugf("loaded properties file %s", workflow.PropertiesPath)
+
+		for _, err := range g.ValidateProperties(properties, categories) {
+			collector.Error(workflowID, err)
+		}
+
+		for _, err := range validateStarterProperties(workflow, properties) {
+			collector.Error(workflowID, err)
+		}
+
+		for _, err := range lintDescription(properties.Description) {
+			collector.Warn(workflowID, err)
+		}
+
+		if err := LintCategoryOrder(properties.Categories); err != nil {
+			collector.Warn(workflowID, err)
+		}
+
+		for _, err := range lintProductKeywords(workflow.WorkflowPath, properties, productKeywords) {
+			collector.Warn(workflowID, err)
+		}
+
+		for _, err := range validateRequiredSecrets(workflow.WorkflowPath, properties.RequiredSecrets) {
+			collector.Error(workflowID, err)
+		}
+
+		for _, err := range validateVariables(workflow.WorkflowPath, properties.Variables) {
+			collector.Error(workflowID, err)
+		}
+
+		for _, err := range lintUndeclaredVariables(workflow.WorkflowPath, properties.Variables, g.CheckVariables) {
+			collector.Warn(workflowID, err)
+		}
+
+		workflowNames[properties.Name] = append(workflowNames[properties.Name], workflowID)
+	}
+
+	for _, err := range validateUniqueNames(workflowNames) {
+		collector.Error("", err)
+	}
+
+	for _, err := range validateCaseInsensitiveIDs(wfConfig) {
+		collector.Error("", err)
+	}
+
+	for _, err := range validateActionStarters(actionWorkflows) {
+		collector.Warn("", err)
+	}
+
+	for _, err := range lintDuplicateWorkflows(workflowContents) {
+		collector.Warn("", err)
+	}
+
+	orphans, err := g.findOrphanedFiles(wfConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find orphaned files: %w", err)
+	}
+	for _, orphan := range orphans {
+		collector.Error("", fmt.Errorf("%w %s is not referenced by any workflow in %s", ErrOrphanedFile, orphan, g.ConfigPath))
+	}
+
+	return wfConfig, collector, nil
+}
+
+// findOrphanedFiles returns the properties files and workflow files that are not referenced by the config
+func (g *Generator) findOrphanedFiles(wfConfig WorkflowConfig) ([]string, error) {
+	referenced := map[string]bool{}
+	for _, workflow := range wfConfig {
+		referenced[path.Clean(workflow.WorkflowPath)] = true
+		referenced[path.Clean(workflow.PropertiesPath)] = true
+	}
+
+	orphans := make([]string, 0)
+
+	propertiesFiles := make([]string, 0)
+	for _, suffix := range []string{".properties.json", ".properties.yaml"} {
+		matches, err := filepath.Glob(filepath.Join(g.PropertiesDir, "*"+suffix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list properties files: %w", err)
+		}
+		propertiesFiles = append(propertiesFiles, matches...)
+	}
+	sort.Strings(propertiesFiles)
+	for _, propertiesFile := range propertiesFiles {
+		if !referenced[filepath.ToSlash(propertiesFile)] {
+			orphans = append(orphans, filepath.ToSlash(propertiesFile))
+		}
+	}
+
+	err := filepath.WalkDir(g.WorkflowsDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(filePath) == ".yml" && !referenced[filepath.ToSlash(filePath)] {
+			orphans = append(orphans, filepath.ToSlash(filePath))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflow files: %w", err)
+	}
+
+	return orphans, nil
+}
+
+// validateConfigPaths checks the workflow and properties paths of a workflow with ValidateConfigPath
+func (g *Generator) validateConfigPaths(w Workflow) []error {
+	errs := make([]error, 0)
+	if err := g.ValidateConfigPath(w.WorkflowPath); err != nil {
+		errs = append(errs, fmt.Errorf("invalid workflow path: %w", err))
+	}
+	if err := g.ValidateConfigPath(w.PropertiesPath); err != nil {
+		errs = append(errs, fmt.Errorf("invalid properties path: %w", err))
+	}
+	return errs
+}
+
+// ValidatePropertiesFileName checks that the properties file is named after the workflow ID, as the workflow command creates it
+func ValidatePropertiesFileName(workflowID string, w Workflow) error {
+	expected := workflowID + PropertiesFileSuffix(w.PropertiesPath)
+	if actual := path.Base(w.PropertiesPath); actual != expected {
+		return fmt.Errorf("%w, expected %s, got %s", ErrPropertiesFileName, expected, actual)
+	}
+
+	return nil
+}
+
+// LintCategoryOrder checks that the properties categories are sorted ignoring case, so the gallery filters stay tidy
+func LintCategoryOrder(categories []string) error {
+	sorted := SortCategories(categories)
+	for i := range categories {
+		if categories[i] != sorted[i] {
+			return fmt.Errorf("%w, expected %s", ErrUnsortedCategories, strings.Join(sorted, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ActionPathOf returns the action folder of a workflow path of at least <workflows dir>/action-name/workflow-name.yml,
+// relative to the working directory, which is where its action README is created. The path is checked as it is
+// written in the workflow config, failing when it does not start with an action folder of the workflows directory
+func (g *Generator) ActionPathOf(workflowPath string) (string, error) {
+	workflowsDir := g.ConfigRelativePath(g.WorkflowsDir)
+	configPath := g.ConfigRelativePath(workflowPath)
+
+	actionWorkflowPath := configPath
+	if workflowsDir != "." {
+		actionWorkflowPath = strings.TrimPrefix(configPath, workflowsDir+"/")
+	}
+
+	parts := strings.Split(actionWorkflowPath, "/")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("%w %s, should be at least %s/action-name/workflow-name.yml", ErrInvalidWorkflowPath, configPath, workflowsDir)
+	}
+
+	if (actionWorkflowPath == configPath && workflowsDir != ".") || parts[0] == "" || parts[0] == "." || parts[0] == ".." {
+		// the action folder is as deep as the workflows directory and one more folder
+		configParts := strings.Split(configPath, "/")
+		depth := 1
+		if workflowsDir != "." {
+			depth += len(strings.Split(workflowsDir, "/"))
+		}
+		if depth > len(configParts)-1 {
+			depth = len(configParts) - 1
+		}
+		actionFolder := strings.Join(configParts[:depth], "/")
+		return "", fmt.Errorf("%w %s, the action folder %s should be %s/action-name", ErrInvalidWorkflowPath, configPath, actionFolder, workflowsDir)
+	}
+
+	return g.resolveConfigPath(path.Join(workflowsDir, parts[0])), nil
+}
+
+// ValidateConfigPath checks a path is relative to the directory of the config without a .. element,
+// so the config works on every machine it is checked out on
+func (g *Generator) ValidateConfigPath(p string) error {
+	configPath := g.ConfigRelativePath(p)
+	if path.IsAbs(configPath) || filepath.IsAbs(configPath) {
+		return fmt.Errorf("path %s must be relative to the directory of %s", configPath, g.ConfigPath)
+	}
+
+	for _, part := range strings.Split(filepath.ToSlash(configPath), "/") {
+		if part == ".." {
+			return fmt.Errorf("path %s must not contain .., it should stay under the directory of %s", configPath, g.ConfigPath)
+		}
+	}
+
+	return nil
+}
+
+// validateGenerateReadme handles validations for generating readmes, returning every problem found.
+// Workflow triggers are only checked when triggers is not nil, and the action README when its path is set.
+func (g *Generator) validateGenerateReadme(w Workflow, a ReadmeAction, triggers map[string]bool) []error {
+	errs := make([]error, 0)
+
+	g.Logger.Debugf("validating workflow %s", w.WorkflowPath)
+
+	if _, err := os.Stat(w.WorkflowPath); err != nil {
+		errs = append(errs, fmt.Errorf("failed to validate %s exists: %w", w.WorkflowPath, err))
+	} else {
+		errs = append(errs, g.validateWorkflowYAML(w.WorkflowPath, triggers)...)
+	}
+
+	if _, err := os.Stat(w.PropertiesPath); err != nil {
+		errs = append(errs, fmt.Errorf("failed to validate %s exists: %w", w.PropertiesPath, err))
+	}
+
+	if a.ReadMePath != "" {
+		if _, err := os.Stat(a.ReadMePath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to validate %s exists: %w", a.ReadMePath, err))
+		}
+	}
+
+	return errs
+}
+
+// tabIndentedLines returns the 1-based line numbers of content with a tab in their leading indentation,
+// which YAML forbids. Scanning the raw bytes reports every such line instead of the first parse error.
+// Blank lines and the body of block scalars are skipped, a tab after the indentation of a block scalar
+// is part of its content, e.g. in a run script.
+func tabIndentedLines(content []byte) []int {
+	lines := make([]int, 0)
+
+	inBlock, parentIndent, blockIndent := false, 0, -1
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		spaces := len(line) - len(bytes.TrimLeft(line, " "))
+		if inBlock {
+			// the first line of the body sets the indentation of the block
+			if blockIndent < 0 && spaces > parentIndent {
+				blockIndent = spaces
+			}
+			if blockIndent >= 0 && spaces >= blockIndent {
+				continue
+			}
+			inBlock = false
+		}
+
+		indentation := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		if bytes.IndexByte(indentation, '\t') >= 0 {
+			lines = append(lines, i+1)
+		}
+
+		if blockScalarPattern.Match(line) {
+			inBlock, parentIndent, blockIndent = true, spaces, -1
+		}
+	}
+	return lines
+}
+
+// validateWorkflowYAML parses a workflow file and checks it has the top-level keys required by GitHub Actions
+func (g *Generator) validateWorkflowYAML(workflowPath string, triggers map[string]bool) []error {
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return []error{fmt.Errorf("failed to read %s: %w", workflowPath, err)}
+	}
+
+	if strings.TrimSpace(string(content)) == WorkflowStubContents {
+		return []error{fmt.Errorf("workflow %s is still the generated stub, add the workflow content", workflowPath)}
+	}
+
+	if len(bytes.TrimSpace(content)) < minWorkflowBytes {
+		return []error{fmt.Errorf("workflow %s is too short to be a complete workflow, expected at least %d bytes", workflowPath, minWorkflowBytes)}
+	}
+
+	if lines := tabIndentedLines(content); len(lines) > 0 {
+		errs := make([]error, 0, len(lines))
+		for _, line := range lines {
+			errs = append(errs, &LocatedError{
+				Path: workflowPath,
+				Line: line,
+				Err:  fmt.Errorf("invalid YAML in %s: line %d is indented with a tab, use spaces", workflowPath, line),
+			})
+		}
+		return errs
+	}
+
+	doc, err := parseYAML(content)
+	if err != nil {
+		return []error{&LocatedError{
+			Path: workflowPath,
+			Line: yamlErrorLine(err),
+			Err:  fmt.Errorf("invalid YAML in %s: %w", workflowPath, err),
+		}}
+	}
+
+	if doc != nil && doc.Kind != yaml.MappingNode {
+		return []error{fmt.Errorf("invalid workflow %s: document must be a YAML mapping", workflowPath)}
+	}
+
+	errs := make([]error, 0)
+	for _, key := range []string{"on", "jobs"} {
+		if yamlGet(doc, key) == nil {
+			errs = append(errs, fmt.Errorf("invalid workflow %s: missing top-level %q key", workflowPath, key))
+		}
+	}
+
+	if on := yamlGet(doc, "on"); on != nil && triggers != nil {
+		for _, trigger := range workflowTriggers(on) {
+			if !triggers[trigger] {
+				errs = append(errs, fmt.Errorf("invalid workflow %s: trigger %q is not allowed, see %s or the default triggers for allowed values", workflowPath, trigger, g.TriggersPath))
+			}
+		}
+	}
+
+	return errs
+}
+
+// workflowTriggers returns the trigger names of an 'on' node in its scalar, list or map form
+func workflowTriggers(on *yaml.Node) []string {
+	switch on.Kind {
+	case yaml.MappingNode:
+		return yamlKeys(on)
+	case yaml.SequenceNode:
+		triggers := make([]string, 0, len(on.Content))
+		for _, item := range on.Content {
+			triggers = append(triggers, item.Value)
+		}
+		return triggers
+	default:
+		return []string{on.Value}
+	}
+}
+
+// ValidateProperties handles validations for the contents of a properties file
+func (g *Generator) ValidateProperties(p PropertiesConfig, categories map[string]bool) []error {
+	errs := make([]error, 0)
+
+	if strings.TrimSpace(p.Name) == "" {
+		errs = append(errs, fmt.Errorf("properties name must not be empty"))
+	} else if strings.TrimSpace(p.Name) != p.Name {
+		errs = append(errs, fmt.Errorf("properties name %q must not have leading or trailing whitespace", p.Name))
+	}
+
+	if nameLength := utf8.RuneCountInString(p.Name); nameLength > g.MaxNameLength {
+		errs = append(errs, fmt.Errorf("properties name %q is %d characters, must be at most %d", p.Name, nameLength, g.MaxNameLength))
+	}
+
+	if strings.TrimSpace(p.Description) == "" {
+		errs = append(errs, fmt.Errorf("properties description must not be empty"))
+	}
+
+	if len(p.Categories) == 0 {
+		errs = append(errs, fmt.Errorf("properties categories must not be empty"))
+	}
+
+	// icons are only validated when pointed at the starter workflows icons directory
+	if g.IconsDir != "" {
+		iconPath := filepath.Join(g.IconsDir, fmt.Sprintf("%s.svg", p.IconName))
+		if _, err := os.Stat(iconPath); err != nil {
+			errs = append(errs, fmt.Errorf("icon %q does not exist: %w", p.IconName, err))
+		}
+	}
+
+	for _, category := range p.Categories {
+		if !categories[category] {
+			errs = append(errs, fmt.Errorf("invalid category %q, see %s or the default categories for allowed values", category, g.CategoriesPath))
+		}
+	}
+
+	for _, variable := range p.Variables {
+		if !envVariableNamePattern.MatchString(variable.Name) {
+			errs = append(errs, fmt.Errorf("invalid properties variable name %q, must be a valid environment variable name", variable.Name))
+		}
+	}
+
+	return errs
+}
+
+// validateStarterProperties checks the properties of a starter workflow have the fields the
+// starter workflows gallery requires, the name, description and categories are already required
+// of every workflow by ValidateProperties
+func validateStarterProperties(w Workflow, p PropertiesConfig) []error {
+	errs := make([]error, 0)
+	if !w.Starter {
+		return errs
+	}
+
+	if strings.TrimSpace(p.IconName) == "" {
+		errs = append(errs, fmt.Errorf("properties iconName must not be empty for a starter workflow"))
+	}
+
+	return errs
+}
+
+// lintWorkflow matches the scalar values of a workflow file against the lint rules and checks
+// its name and permissions, files that cannot be read or parsed have already been reported
+func lintWorkflow(workflowID string, workflowPath string, rules []compiledLintRule) []error {
+	errs := make([]error, 0)
+
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return errs
+	}
+
+	doc, err := parseYAML(content)
+	if err != nil {
+		return errs
+	}
+
+	for _, scalar := range yamlScalars(doc) {
+		for _, rule := range rules {
+			if match := rule.pattern.FindString(scalar.Value); match != "" {
+				errs = append(errs, fmt.Errorf("%s:%d: %s: %s (matched %q)", workflowPath, scalar.Line, rule.Name, rule.Message, match))
+			}
+		}
+	}
+
+	if err := lintWorkflowName(workflowID, doc); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", workflowPath, err))
+	}
+
+	for _, err := range lintPermissions(doc) {
+		errs = append(errs, fmt.Errorf("%s: %w", workflowPath, err))
+	}
+
+	return errs
+}
+
+// lintWorkflowName checks that the top-level name of a workflow is set and shares at least one word with
+// the name derived from its workflow ID, to catch names never customized from a copied workflow
+func lintWorkflowName(workflowID string, doc *yaml.Node) error {
+	name := ""
+	if node := yamlGet(doc, "name"); node != nil && node.Kind == yaml.ScalarNode {
+		name = strings.TrimSpace(node.Value)
+	}
+
+	if name == "" {
+		return fmt.Errorf("workflow has no name, add a top-level name such as %q", Humanize(workflowID))
+	}
+
+	nameWords := nameWordSet(name)
+	for word := range nameWordSet(Humanize(workflowID)) {
+		if nameWords[word] {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("workflow name %q has no words in common with %q, derived from workflow ID %s", name, Humanize(workflowID), workflowID)
+}
+
+// nameWordSet returns the lower cased words of a name, without the stop words ignored when comparing names
+func nameWordSet(name string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !nameStopWords[word] {
+			words[word] = true
+		}
+	}
+
+	return words
+}
+
+// lintPermissions checks that every job runs with declared permissions, from its own or the
+// top-level permissions key, and that jobs using google-github-actions/auth can request an ID token
+func lintPermissions(doc *yaml.Node) []error {
+	errs := make([]error, 0)
+	jobs := yamlGet(doc, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return errs
+	}
+
+	workflowPermissions := yamlGet(doc, "permissions")
+	for _, jobName := range yamlKeys(jobs) {
+		job := yamlGet(jobs, jobName)
+
+		permissions := yamlGet(job, "permissions")
+		if permissions == nil {
+			permissions = workflowPermissions
+		}
+
+		if permissions == nil {
+			errs = append(errs, fmt.Errorf("job %s has no permissions, add a top-level or job permissions key to restrict the GITHUB_TOKEN", jobName))
+			continue
+		}
+
+		if usesAuth(job) && !grantsIDToken(permissions) {
+			errs = append(errs, fmt.Errorf("job %s uses google-github-actions/auth but does not grant 'id-token: write'", jobName))
+		}
+	}
+
+	return errs
+}
+
+// usesAuth reports whether a step of a job uses the google-github-actions/auth action
+func usesAuth(job *yaml.Node) bool {
+	for _, uses := range stepUses(job) {
+		if strings.HasPrefix(uses, "google-github-actions/auth@") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stepUses returns the uses values of the steps of a job
+func stepUses(job *yaml.Node) []string {
+	values := make([]string, 0)
+	steps := yamlGet(job, "steps")
+	if steps == nil || steps.Kind != yaml.SequenceNode {
+		return values
+	}
+
+	for _, step := range steps.Content {
+		if uses := yamlGet(step, "uses"); uses != nil {
+			values = append(values, uses.Value)
+		}
+	}
+
+	return values
+}
+
+// validateRequiredSecrets checks that every secret declared in the properties is referenced in the workflow,
+// as secrets.NAME or secrets['NAME'], a file that cannot be read has already been reported
+func validateRequiredSecrets(workflowPath string, requiredSecrets []string) []error {
+	errs := make([]error, 0)
+	if len(requiredSecrets) == 0 {
+		return errs
+	}
+
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return errs
+	}
+
+	for _, secret := range requiredSecrets {
+		name := regexp.QuoteMeta(secret)
+		// secret names are case insensitive
+		pattern := regexp.MustCompile(`(?i)secrets(?:\.` + name + `\b|\[\s*['"]` + name + `['"]\s*\])`)
+		if !pattern.Match(content) {
+			errs = append(errs, fmt.Errorf("required secret %s is not referenced in %s", secret, workflowPath))
+		}
+	}
+
+	return errs
+}
+
+// workflowEnvVariables returns the names of the env variables referenced in a workflow as env.NAME,
+// including in expressions such as ${{ env.NAME }}
+func workflowEnvVariables(content []byte) map[string]bool {
+	variables := map[string]bool{}
+	for _, match := range envVariablePattern.FindAllSubmatch(content, -1) {
+		variables[string(match[1])] = true
+	}
+	return variables
+}
+
+// validateVariables checks that every variable declared in the properties is referenced in the workflow,
+// a file that cannot be read has already been reported
+func validateVariables(workflowPath string, variables []PropertiesVariable) []error {
+	errs := make([]error, 0)
+	if len(variables) == 0 {
+		return errs
+	}
+
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return errs
+	}
+
+	used := workflowEnvVariables(content)
+	for _, variable := range variables {
+		// invalid names have already been reported by ValidateProperties
+		if envVariableNamePattern.MatchString(variable.Name) && !used[variable.Name] {
+			errs = append(errs, fmt.Errorf("variable %s is not referenced as env.%s in %s", variable.Name, variable.Name, workflowPath))
+		}
+	}
+
+	return errs
+}
+
+// lintUndeclaredVariables warns about env variables referenced in a workflow but not declared in its
+// properties variables. Workflows without declared variables are only checked with checkAll, as most
+// workflows set their env variables themselves.
+func lintUndeclaredVariables(workflowPath string, variables []PropertiesVariable, checkAll bool) []error {
+	errs := make([]error, 0)
+	if len(variables) == 0 && !checkAll {
+		return errs
+	}
+
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return errs
+	}
+
+	declared := make(map[string]bool, len(variables))
+	for _, variable := range variables {
+		declared[variable.Name] = true
+	}
+
+	used := workflowEnvVariables(content)
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !declared[name] {
+			errs = append(errs, fmt.Errorf("variable %s is referenced in %s but not declared in the properties variables", name, workflowPath))
+		}
+	}
+
+	return errs
+}
+
+// lintProductKeywords checks that the properties name and description mention the product of an
+// action the workflow uses, catching properties copied from another workflow and not fully edited.
+// Workflows that use none of the known actions and properties that mention none of the known
+// products are not checked.
+func lintProductKeywords(workflowPath string, properties PropertiesConfig, productKeywords map[string][]string) []error {
+	errs := make([]error, 0)
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return errs
+	}
+
+	doc, err := parseYAML(content)
+	if err != nil {
+		return errs
+	}
+
+	usedActions := map[string]bool{}
+	if jobs := yamlGet(doc, "jobs"); jobs != nil && jobs.Kind == yaml.MappingNode {
+		for _, jobName := range yamlKeys(jobs) {
+			for _, uses := range stepUses(yamlGet(jobs, jobName)) {
+				// owner/action@ref -> action
+				action := strings.SplitN(uses, "@", 2)[0]
+				usedActions[path.Base(action)] = true
+			}
+		}
+	}
+
+	actions := make([]string, 0, len(productKeywords))
+	for action := range productKeywords {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	text := properties.Name + " " + properties.Description
+	used := make([]string, 0)
+	mentioned := make([]string, 0)
+	for _, action := range actions {
+		isMentioned := false
+		for _, keyword := range productKeywords[action] {
+			if strings.Contains(text, keyword) {
+				isMentioned = true
+				break
+			}
+		}
+
+		if usedActions[action] && isMentioned {
+			return errs
+		}
+		if usedActions[action] {
+			used = append(used, action)
+		}
+		if isMentioned {
+			mentioned = append(mentioned, action)
+		}
+	}
+
+	if len(used) > 0 && len(mentioned) > 0 {
+		errs = append(errs, fmt.Errorf("properties name and description mention the product of %s, but %s uses %s", strings.Join(mentioned, ", "), workflowPath, strings.Join(used, ", ")))
+	}
+
+	return errs
+}
+
+// loadProductKeywords loads the keywords for the product of each action from product-keywords.json,
+// falling back to defaultProductKeywords
+func (g *Generator) loadProductKeywords() (map[string][]string, error) {
+	if _, err := os.Stat(g.ProductKeywordsPath); os.IsNotExist(err) {
+		return defaultProductKeywords, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to validate %s exists: %w", g.ProductKeywordsPath, err)
+	}
+
+	productKeywords := map[string][]string{}
+	if err := LoadConfigFile(&productKeywords, g.ProductKeywordsPath); err != nil {
+		return nil, fmt.Errorf("failed to load product keywords file %s: %w", g.ProductKeywordsPath, err)
+	}
+
+	return productKeywords, nil
+}
+
+// licenseHeaderYearPattern matches the copyright year of a license header, which may be a range
+var licenseHeaderYearPattern = regexp.MustCompile(`\b(?:19|20)[0-9]{2}(?:-(?:19|20)[0-9]{2})?\b`)
+
+// loadLicenseHeader loads the license header every workflow file must start with from license-header.txt,
+// returning a nil pattern when the file does not exist and the header is not required. The years of the
+// header match any year, so the header does not have to change for workflows added in a later year
+func (g *Generator) loadLicenseHeader() (*regexp.Regexp, error) {
+	headerBytes, err := os.ReadFile(g.LicenseHeaderPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read license header file %s: %w", g.LicenseHeaderPath, err)
+	}
+
+	header := strings.TrimRight(strings.ReplaceAll(string(headerBytes), "\r\n", "\n"), "\n")
+	if header == "" {
+		return nil, nil
+	}
+
+	parts := licenseHeaderYearPattern.Split(header, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, licenseHeaderYearPattern.String())), nil
+}
+
+// validateLicenseHeader checks that a workflow file starts with the license header,
+// a workflow file that cannot be read has already been reported
+func (g *Generator) validateLicenseHeader(workflowPath string, header *regexp.Regexp) error {
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return nil
+	}
+
+	if !header.MatchString(strings.ReplaceAll(string(content), "\r\n", "\n")) {
+		return fmt.Errorf("%w in %s, it should start with the contents of %s", ErrNoLicenseHeader, workflowPath, g.LicenseHeaderPath)
+	}
+
+	return nil
+}
+
+// grantsIDToken reports whether a permissions node allows requesting an OIDC ID token
+func grantsIDToken(permissions *yaml.Node) bool {
+	if permissions.Kind == yaml.ScalarNode {
+		return permissions.Value == "write-all"
+	}
+
+	idToken := yamlGet(permissions, "id-token")
+	return idToken != nil && idToken.Value == "write"
+}
+
+// loadLintRules loads and compiles the lint rules from lint-rules.json, falling back to defaultLintRules
+func (g *Generator) loadLintRules() ([]compiledLintRule, error) {
+	rules := make([]lintRule, 0)
+	if _, err := os.Stat(g.LintRulesPath); os.IsNotExist(err) {
+		rules = defaultLintRules
+	} else if err == nil {
+		if err := LoadConfigFile(&rules, g.LintRulesPath); err != nil {
+			return nil, fmt.Errorf("failed to load lint rules file %s: %w", g.LintRulesPath, err)
+		}
+	} else {
+		return nil, fmt.Errorf("failed to validate %s exists: %w", g.LintRulesPath, err)
+	}
+
+	compiled := make([]compiledLintRule, 0, len(rules))
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for lint rule %s: %w", rule.Name, err)
+		}
+		compiled = append(compiled, compiledLintRule{lintRule: rule, pattern: pattern})
+	}
+
+	return compiled, nil
+}
+
+// lintDescription checks a properties description reads as a complete sentence
+func lintDescription(description string) []error {
+	errs := make([]error, 0)
+	description = strings.TrimSpace(description)
+	if description == "" {
+		// the empty description has already been reported
+		return errs
+	}
+
+	if first, _ := utf8.DecodeRuneInString(description); !unicode.IsUpper(first) {
+		errs = append(errs, fmt.Errorf("properties description %q should start with an uppercase letter", description))
+	}
+
+	if !strings.HasSuffix(description, ".") && !strings.HasSuffix(description, "!") && !strings.HasSuffix(description, "?") {
+		errs = append(errs, fmt.Errorf("properties description %q should end with '.', '!' or '?'", description))
+	}
+
+	return errs
+}
+
+// validateUniqueNames checks that no properties name is used by more than one workflow
+func validateUniqueNames(workflowNames map[string][]string) []error {
+	names := make([]string, 0, len(workflowNames))
+	for name := range workflowNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]error, 0)
+	for _, name := range names {
+		if workflowIDs := workflowNames[name]; len(workflowIDs) > 1 {
+			errs = append(errs, fmt.Errorf("properties name %q is used by multiple workflows: %s", name, strings.Join(workflowIDs, ", ")))
+		}
+	}
+
+	return errs
+}
+
+// validateCaseInsensitiveIDs checks that no two workflow IDs, and no two starter workflow files
+// released to the same type directory, only differ in case, since they overwrite each other on
+// case-insensitive filesystems
+func validateCaseInsensitiveIDs(wc WorkflowConfig) []error {
+	ids := map[string][]string{}
+	releaseFiles := map[string][]string{}
+	for _, workflowID := range SortedWorkflowIDs(wc) {
+		workflow := wc[workflowID]
+		ids[strings.ToLower(workflowID)] = append(ids[strings.ToLower(workflowID)], workflowID)
+
+		if workflow.Starter {
+			releaseFile := strings.ToLower(path.Join(workflow.Type, path.Base(workflow.WorkflowPath)))
+			releaseFiles[releaseFile] = append(releaseFiles[releaseFile], workflowID)
+		}
+	}
+
+	errs := make([]error, 0)
+	for _, workflowIDs := range sortedCollisions(ids) {
+		errs = append(errs, fmt.Errorf("workflow IDs %s only differ in case and collide on case-insensitive filesystems", strings.Join(workflowIDs, ", ")))
+	}
+
+	for _, workflowIDs := range sortedCollisions(releaseFiles) {
+		errs = append(errs, fmt.Errorf("starter workflows %s are released to files that only differ in case and collide on case-insensitive filesystems", strings.Join(workflowIDs, ", ")))
+	}
+
+	return errs
+}
+
+// workflowContentHash returns the SHA-256 of a workflow file without its license header and ignoring
+// whitespace, so that workflows differing only in formatting have the same hash
+func workflowContentHash(workflowPath string) (string, error) {
+	content, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", workflowPath, err)
+	}
+
+	fileLines := stripLicenseHeader(strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"))
+	normalized := strings.Join(strings.Fields(strings.Join(fileLines, "\n")), " ")
+
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// lintDuplicateWorkflows warns about workflows with identical content, keyed by workflowContentHash
+func lintDuplicateWorkflows(workflowContents map[string][]string) []error {
+	duplicates := make([][]string, 0)
+	for _, workflowIDs := range workflowContents {
+		if len(workflowIDs) > 1 {
+			duplicates = append(duplicates, workflowIDs)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return duplicates[i][0] < duplicates[j][0]
+	})
+
+	errs := make([]error, 0, len(duplicates))
+	for _, workflowIDs := range duplicates {
+		errs = append(errs, fmt.Errorf("workflows %s have identical content, keep one of them or make them differ", strings.Join(workflowIDs, ", ")))
+	}
+
+	return errs
+}
+
+// validateActionStarters checks that every action has at least one starter workflow, since actions without
+// one do not appear in the starter workflows gallery
+func validateActionStarters(actionWorkflows map[string][]Workflow) []error {
+	actionNames := make([]string, 0, len(actionWorkflows))
+	for actionName := range actionWorkflows {
+		actionNames = append(actionNames, actionName)
+	}
+	sort.Strings(actionNames)
+
+	errs := make([]error, 0)
+	for _, actionName := range actionNames {
+		hasStarter := false
+		for _, workflow := range actionWorkflows[actionName] {
+			if workflow.Starter {
+				hasStarter = true
+				break
+			}
+		}
+
+		if !hasStarter {
+			errs = append(errs, fmt.Errorf("%w in action %s, which has %d workflow(s) and will not appear in the starter workflows gallery", ErrNoStarterWorkflow, actionName, len(actionWorkflows[actionName])))
+		}
+	}
+
+	return errs
+}
+
+// LoadValidCategories loads the allowed categories from categories.json, falling back to validCategories
+func (g *Generator) LoadValidCategories() (map[string]bool, error) {
+	return g.loadAllowlist(g.CategoriesPath, validCategories)
+}
+
+// loadValidTriggers loads the allowed workflow triggers from triggers.json, falling back to validTriggers
+func (g *Generator) loadValidTriggers() (map[string]bool, error) {
+	return g.loadAllowlist(g.TriggersPath, validTriggers)
+}
+
+// loadAllowlist loads a JSON array of allowed values from a file, falling back to defaults when it does not exist
+func (g *Generator) loadAllowlist(filePath string, defaults []string) (map[string]bool, error) {
+	allowed := make([]string, 0)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		allowed = defaults
+	} else if err == nil {
+		if err := LoadConfigFile(&allowed, filePath); err != nil {
+			return nil, fmt.Errorf("failed to load allowlist file %s: %w", filePath, err)
+		}
+	} else {
+		return nil, fmt.Errorf("failed to validate %s exists: %w", filePath, err)
+	}
+
+	values := make(map[string]bool, len(allowed))
+	for _, value := range allowed {
+		values[value] = true
+	}
+
+	return values, nil
+}
+
+// getChangedWorkflowIDs returns the workflows whose workflow or properties file changed since ref,
+// or nil when git is unavailable so that every workflow is validated
+func (g *Generator) getChangedWorkflowIDs(wfConfig WorkflowConfig, ref string) map[string]bool {
+	changedFiles, err := listChangedFiles(ref)
+	if err != nil {
+		g.Logger.Warnf("failed to list files changed since %s, validating all workflows: %s", ref, err)
+		return nil
+	}
+
+	changed := make(map[string]bool, len(changedFiles))
+	for _, file := range changedFiles {
+		changed[path.Clean(file)] = true
+	}
+
+	workflowIDs := map[string]bool{}
+	for workflowID, workflow := range wfConfig {
+		if changed[path.Clean(workflow.WorkflowPath)] || changed[path.Clean(workflow.PropertiesPath)] {
+			workflowIDs[workflowID] = true
+		}
+	}
+
+	return workflowIDs
+}
+
+// listChangedFiles lists the files changed since ref, a variable so tests can stub git
+var listChangedFiles = gitChangedFiles
+
+// gitChangedFiles lists the files changed since ref and the untracked files not ignored by git,
+// relative to the working directory
+func gitChangedFiles(ref string) ([]string, error) {
+	changed, err := exec.Command("git", "diff", "--name-only", "--relative", "-z", ref).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git diff: %w", err)
+	}
+
+	untracked, err := exec.Command("git", "ls-files", "--others", "--exclude-standard", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git ls-files: %w", err)
+	}
+
+	files := make([]string, 0)
+	// -z separates the paths with NUL instead of quoting unusual characters
+	for _, file := range strings.Split(string(changed)+string(untracked), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// runActionlint runs the actionlint binary on a workflow file and returns an error for each of its findings
+func runActionlint(actionlintPath string, workflowPath string) []error {
+	output, err := exec.Command(actionlintPath, "-format", "{{json .}}", workflowPath).Output()
+
+	// actionlint exits with 1 when it finds problems, and with 2 or 3 when it fails to run
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() != 1) {
+		return []error{fmt.Errorf("failed to run actionlint on %s: %w", workflowPath, err)}
+	}
+
+	var findings []actionlintFinding
+	if len(bytes.TrimSpace(output)) > 0 {
+		if err := json.Unmarshal(output, &findings); err != nil {
+			return []error{fmt.Errorf("failed to parse actionlint output for %s: %w", workflowPath, err)}
+		}
+	}
+
+	errs := make([]error, 0, len(findings))
+	for _, finding := range findings {
+		errs = append(errs, &LocatedError{
+			Path: workflowPath,
+			Line: finding.Line,
+			Err:  fmt.Errorf("actionlint found a problem in %s at line %d: %s [%s]", workflowPath, finding.Line, finding.Message, finding.Kind),
+		})
+	}
+
+	return errs
+}
@@ -0,0 +1,967 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// stubListChangedFiles replaces listChangedFiles for the rest of the test
+func stubListChangedFiles(t *testing.T, files []string, err error) {
+	t.Helper()
+
+	original := listChangedFiles
+	listChangedFiles = func(string) ([]string, error) { return files, err }
+	t.Cleanup(func() { listChangedFiles = original })
+}
+
+// errorWorkflowIDs returns the sorted workflow IDs of the problems that are not warnings
+func errorWorkflowIDs(problems []Problem) []string {
+	workflowIDs := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		if !problem.Warning {
+			workflowIDs = append(workflowIDs, problem.WorkflowID)
+		}
+	}
+	sort.Strings(workflowIDs)
+	return workflowIDs
+}
+
+func TestValidateSince(t *testing.T) {
+	cases := []struct {
+		name         string
+		files        map[string]string
+		changedFiles []string
+		changedErr   error
+		want         []string
+		wantLogs     string
+	}{
+		{
+			name:         "changed_properties",
+			changedFiles: []string{"properties/appengine.properties.json"},
+			want:         []string{"appengine"},
+		},
+		{
+			name:         "changed_workflow",
+			changedFiles: []string{"README.md", "workflows/deploy-cloudrun/cloudrun-source.yml"},
+			want:         []string{"cloudrun-source"},
+		},
+		{
+			name: "unchanged",
+			want: []string{},
+		},
+		{
+			// problems of the whole config are reported whatever changed
+			name:  "orphan_unchanged",
+			files: map[string]string{"workflows/deploy-cloudrun/orphan.yml": "name: Orphan\n"},
+			want:  []string{""},
+		},
+		{
+			name:       "git_unavailable",
+			changedErr: errors.New("git not found"),
+			want:       []string{"appengine", "cloudrun-source"},
+			wantLogs:   "warning: failed to list files changed since main, validating all workflows: git not found\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, newTestRepo(t))
+			writeTestFiles(t, ".", map[string]string{
+				"properties/appengine.properties.json":       `{"name": "Deploy to App Engine", "description": "Deploy an app to App Engine.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Deployements"]}`,
+				"properties/cloudrun-source.properties.json": `{"name": "Deploy to Cloud Run from source", "description": "Deploy source code to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Deployements"]}`,
+			})
+			writeTestFiles(t, ".", tc.files)
+			stubListChangedFiles(t, tc.changedFiles, tc.changedErr)
+
+			var logs bytes.Buffer
+			g := NewGenerator(Options{Since: "main", Logger: NewLogger(&logs, LevelInfo)})
+			_, collector, err := g.Validate()
+			if err != nil {
+				t.Fatalf("Validate: %s", err)
+			}
+
+			if got := errorWorkflowIDs(collector.Problems); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Validate reported errors of %q, want %q", got, tc.want)
+			}
+			if got := logs.String(); got != tc.wantLogs {
+				t.Errorf("Validate logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestGitChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skipf("git is not available: %s", err)
+	}
+
+	dir := t.TempDir()
+	chdir(t, dir)
+	writeTestFiles(t, dir, map[string]string{
+		".gitignore":              "ignored.txt\n",
+		"workflows/committed.yml": "name: Committed\n",
+		"workflows/modified.yml":  "name: Modified\n",
+	})
+
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	git("init", "--quiet")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "initial")
+
+	writeTestFiles(t, dir, map[string]string{
+		"workflows/modified.yml":  "name: Modified again\n",
+		"workflows/untracked.yml": "name: Untracked\n",
+		"ignored.txt":             "ignored\n",
+	})
+
+	got, err := gitChangedFiles("HEAD")
+	if err != nil {
+		t.Fatalf("gitChangedFiles: %s", err)
+	}
+	sort.Strings(got)
+
+	want := []string{"workflows/modified.yml", "workflows/untracked.yml"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("gitChangedFiles = %q, want %q", got, want)
+	}
+}
+
+func TestLintWorkflowImageReference(t *testing.T) {
+	cases := []struct {
+		name  string
+		image string
+		want  []string
+	}{
+		{
+			name:  "colon_sha",
+			image: "gcr.io/${{ env.PROJECT_ID }}/${{ env.SERVICE }}:${{ github.sha }}",
+			want:  []string{},
+		},
+		{
+			name:  "colon_env_sha",
+			image: "gcr.io/${PROJECT_ID}/${SERVICE}:${GITHUB_SHA}",
+			want:  []string{},
+		},
+		{
+			name:  "slash_sha",
+			image: "gcr.io/${{ env.PROJECT_ID }}/${{ env.SERVICE }}/${{ github.sha }}",
+			want:  []string{`workflow.yml:12: image-tag-separator: image tag is separated with '/', use ':' before the commit SHA (matched "/${{ github.sha }}")`},
+		},
+		{
+			name:  "slash_env_sha",
+			image: "gcr.io/$PROJECT_ID/$SERVICE/$GITHUB_SHA",
+			want:  []string{`workflow.yml:12: image-tag-separator: image tag is separated with '/', use ':' before the commit SHA (matched "/$GITHUB_SHA")`},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+			writeTestFiles(t, ".", map[string]string{
+				"workflow.yml": `name: Deploy Image
+
+on: push
+
+permissions:
+  contents: read
+
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: docker push "` + tc.image + `"
+`,
+			})
+
+			rules, err := NewGenerator(Options{}).loadLintRules()
+			if err != nil {
+				t.Fatalf("loadLintRules: %s", err)
+			}
+
+			got := make([]string, 0)
+			for _, err := range lintWorkflow("deploy-image", "workflow.yml", rules) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("lintWorkflow = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLintPermissions(t *testing.T) {
+	cases := []struct {
+		name     string
+		workflow string
+		want     []string
+	}{
+		{
+			name: "permissions",
+			workflow: `permissions:
+  contents: read
+jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+`,
+			want: []string{},
+		},
+		{
+			name: "no_permissions",
+			workflow: `jobs:
+  build:
+    steps:
+      - uses: actions/checkout@v4
+`,
+			want: []string{"job build has no permissions, add a top-level or job permissions key to restrict the GITHUB_TOKEN"},
+		},
+		{
+			name: "job_permissions",
+			workflow: `jobs:
+  build:
+    permissions:
+      contents: read
+    steps:
+      - uses: actions/checkout@v4
+`,
+			want: []string{},
+		},
+		{
+			name: "auth_with_id_token",
+			workflow: `permissions:
+  contents: read
+  id-token: write
+jobs:
+  deploy:
+    steps:
+      - uses: google-github-actions/auth@v2
+`,
+			want: []string{},
+		},
+		{
+			name: "auth_without_id_token",
+			workflow: `permissions:
+  contents: read
+jobs:
+  deploy:
+    steps:
+      - uses: google-github-actions/auth@v2
+`,
+			want: []string{"job deploy uses google-github-actions/auth but does not grant 'id-token: write'"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parseYAML([]byte(tc.workflow))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0)
+			for _, err := range lintPermissions(doc) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("lintPermissions = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLintProductKeywords(t *testing.T) {
+	workflow := `name: Deploy
+
+on: push
+
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: google-github-actions/get-gke-credentials@v2
+`
+
+	cases := []struct {
+		name            string
+		propertiesName  string
+		productKeywords map[string][]string
+		want            []string
+	}{
+		{
+			name:           "matching",
+			propertiesName: "Deploy to GKE",
+			want:           []string{},
+		},
+		{
+			name:           "mismatching",
+			propertiesName: "Deploy to Cloud Run",
+			want:           []string{"properties name and description mention the product of deploy-cloudrun, but workflow.yml uses get-gke-credentials"},
+		},
+		{
+			name:            "custom_keywords",
+			propertiesName:  "Deploy to Autopilot",
+			productKeywords: map[string][]string{"deploy-appengine": {"Autopilot"}, "get-gke-credentials": {"Kubernetes"}},
+			want:            []string{"properties name and description mention the product of deploy-appengine, but workflow.yml uses get-gke-credentials"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+			writeTestFiles(t, ".", map[string]string{"workflow.yml": workflow})
+
+			productKeywords := tc.productKeywords
+			if productKeywords == nil {
+				productKeywords = defaultProductKeywords
+			}
+
+			got := make([]string, 0)
+			properties := PropertiesConfig{Name: tc.propertiesName, Description: "Deploy an app."}
+			for _, err := range lintProductKeywords("workflow.yml", properties, productKeywords) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("lintProductKeywords = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLintWorkflowName(t *testing.T) {
+	cases := []struct {
+		name     string
+		workflow string
+		want     string
+	}{
+		{
+			name:     "matching",
+			workflow: "name: Deploy to Cloud Run from Source\n",
+		},
+		{
+			name:     "empty",
+			workflow: "name: ''\n",
+			want:     `workflow has no name, add a top-level name such as "Cloud Run Source"`,
+		},
+		{
+			name:     "missing",
+			workflow: "on: push\n",
+			want:     `workflow has no name, add a top-level name such as "Cloud Run Source"`,
+		},
+		{
+			name:     "mismatched",
+			workflow: "name: Build a Docker Image\n",
+			want:     `workflow name "Build a Docker Image" has no words in common with "Cloud Run Source", derived from workflow ID cloudrun-source`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := parseYAML([]byte(tc.workflow))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := ""
+			if err := lintWorkflowName("cloudrun-source", doc); err != nil {
+				got = err.Error()
+			}
+			if got != tc.want {
+				t.Errorf("lintWorkflowName = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateRequiredSecrets(t *testing.T) {
+	workflow := `name: Deploy
+
+on: push
+
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: google-github-actions/auth@v2
+        with:
+          credentials_json: ${{ secrets.GCP_CREDENTIALS_JSON }}
+      - run: ./deploy.sh
+        env:
+          API_TOKEN: ${{ secrets['API_TOKEN'] }}
+`
+
+	cases := []struct {
+		name    string
+		secrets []string
+		want    []string
+	}{
+		{
+			name:    "used",
+			secrets: []string{"GCP_CREDENTIALS_JSON", "API_TOKEN"},
+			want:    []string{},
+		},
+		{
+			name:    "used_other_case",
+			secrets: []string{"gcp_credentials_json"},
+			want:    []string{},
+		},
+		{
+			name:    "unused",
+			secrets: []string{"GCP_CREDENTIALS_JSON", "SLACK_WEBHOOK"},
+			want:    []string{"required secret SLACK_WEBHOOK is not referenced in workflow.yml"},
+		},
+		{
+			name:    "prefix_of_used",
+			secrets: []string{"GCP_CREDENTIALS"},
+			want:    []string{"required secret GCP_CREDENTIALS is not referenced in workflow.yml"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+			writeTestFiles(t, ".", map[string]string{"workflow.yml": workflow})
+
+			got := make([]string, 0)
+			for _, err := range validateRequiredSecrets("workflow.yml", tc.secrets) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("validateRequiredSecrets = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTabIndentedLines(t *testing.T) {
+	cases := []struct {
+		name     string
+		workflow string
+		want     []int
+	}{
+		{
+			name: "clean",
+			workflow: `name: Deploy
+on: push
+jobs:
+  deploy:
+    steps:
+      - run: echo deploy
+`,
+			want: []int{},
+		},
+		{
+			name:     "tab_indented",
+			workflow: "name: Deploy\non: push\njobs:\n\tdeploy:\n  \tsteps:\n      - run: echo deploy\n",
+			want:     []int{4, 5},
+		},
+		{
+			name:     "tab_in_block_scalar",
+			workflow: "jobs:\n  deploy:\n    steps:\n      - run: |\n          cat <<EOF\n          \tindented with a tab\n          EOF\n\n          echo done\n      - run: >-\n          echo\n          \tfolded\n",
+			want:     []int{},
+		},
+		{
+			name:     "tab_after_block_scalar",
+			workflow: "jobs:\n  deploy:\n    steps:\n      - run: |\n          echo deploy\n\t\t- run: echo done\n",
+			want:     []int{6},
+		},
+		{
+			name:     "blank_line_with_tab",
+			workflow: "name: Deploy\n\t\non: push\n",
+			want:     []int{},
+		},
+		{
+			name:     "pipe_in_value",
+			workflow: "jobs:\n  deploy:\n    steps:\n      - run: echo a |\n\t  cat\n",
+			want:     []int{5},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tabIndentedLines([]byte(tc.workflow)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("tabIndentedLines = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestActionPathOf(t *testing.T) {
+	cases := []struct {
+		name         string
+		opts         Options
+		workflowPath string
+		want         string
+		wantErr      string
+	}{
+		{
+			name:         "well_formed",
+			workflowPath: "workflows/deploy-cloudrun/cloudrun-docker.yml",
+			want:         "workflows/deploy-cloudrun",
+		},
+		{
+			name:         "nested",
+			workflowPath: "workflows/deploy-cloudrun/go/cloudrun-docker.yml",
+			want:         "workflows/deploy-cloudrun",
+		},
+		{
+			name:         "no_action_folder",
+			workflowPath: "workflows/cloudrun-docker.yml",
+			wantErr:      "invalid workflow path workflows/cloudrun-docker.yml, should be at least workflows/action-name/workflow-name.yml",
+		},
+		{
+			name:         "outside_workflows_dir",
+			workflowPath: "examples/deploy-cloudrun/cloudrun-docker.yml",
+			wantErr:      "invalid workflow path examples/deploy-cloudrun/cloudrun-docker.yml, the action folder examples/deploy-cloudrun should be workflows/action-name",
+		},
+		{
+			name:         "parent_action_folder",
+			workflowPath: "workflows/../cloudrun-docker.yml",
+			wantErr:      "invalid workflow path workflows/../cloudrun-docker.yml, the action folder workflows/.. should be workflows/action-name",
+		},
+		{
+			name:         "workflows_dir",
+			opts:         Options{WorkflowsDir: "ci/flows"},
+			workflowPath: "ci/flows/deploy-cloudrun/cloudrun-docker.yml",
+			want:         "ci/flows/deploy-cloudrun",
+		},
+		{
+			name:         "outside_custom_workflows_dir",
+			opts:         Options{WorkflowsDir: "ci/flows"},
+			workflowPath: "workflows/deploy-cloudrun/cloudrun-docker.yml",
+			wantErr:      "invalid workflow path workflows/deploy-cloudrun/cloudrun-docker.yml, the action folder workflows/deploy-cloudrun should be ci/flows/action-name",
+		},
+		{
+			name:         "config_dir",
+			opts:         Options{ConfigPath: "scratch/workflow.config.json", WorkflowsDir: "scratch/flows"},
+			workflowPath: "scratch/flows/deploy-cloudrun/cloudrun-docker.yml",
+			want:         "scratch/flows/deploy-cloudrun",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewGenerator(tc.opts).ActionPathOf(tc.workflowPath)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr || !errors.Is(err, ErrInvalidWorkflowPath) {
+					t.Fatalf("ActionPathOf error = %v, want %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ActionPathOf: %s", err)
+			}
+			if got != tc.want {
+				t.Errorf("ActionPathOf = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateLicenseHeader(t *testing.T) {
+	header := `# Copyright 2023 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+`
+	workflow := "name: Deploy\n\non: push\n"
+
+	cases := []struct {
+		name     string
+		header   string // license-header.txt, not written when empty
+		workflow string
+		wantErr  bool
+	}{
+		{
+			name:     "header",
+			header:   header,
+			workflow: header + "\n" + workflow,
+		},
+		{
+			name:     "other_year",
+			header:   header,
+			workflow: strings.Replace(header, "2023", "2026", 1) + "\n" + workflow,
+		},
+		{
+			name:     "year_range",
+			header:   header,
+			workflow: strings.Replace(header, "2023", "2021-2024", 1) + "\n" + workflow,
+		},
+		{
+			name:     "crlf",
+			header:   header,
+			workflow: strings.ReplaceAll(header+"\n"+workflow, "\n", "\r\n"),
+		},
+		{
+			name:     "missing",
+			header:   header,
+			workflow: workflow,
+			wantErr:  true,
+		},
+		{
+			name:     "other_holder",
+			header:   header,
+			workflow: strings.Replace(header, "Google LLC", "Example Inc", 1) + "\n" + workflow,
+			wantErr:  true,
+		},
+		{
+			name:     "not_required",
+			workflow: workflow,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+			writeTestFiles(t, ".", map[string]string{"workflow.yml": tc.workflow})
+			if tc.header != "" {
+				writeTestFiles(t, ".", map[string]string{"license-header.txt": tc.header})
+			}
+
+			g := NewGenerator(Options{})
+			licenseHeader, err := g.loadLicenseHeader()
+			if err != nil {
+				t.Fatalf("loadLicenseHeader: %s", err)
+			}
+			if licenseHeader == nil {
+				if tc.header != "" {
+					t.Fatal("loadLicenseHeader returned no header, want one")
+				}
+				return
+			}
+
+			err = g.validateLicenseHeader("workflow.yml", licenseHeader)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("validateLicenseHeader returned %v, want an error: %t", err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrNoLicenseHeader) {
+				t.Errorf("validateLicenseHeader returned %v, want ErrNoLicenseHeader", err)
+			}
+		})
+	}
+}
+
+func TestValidateVariables(t *testing.T) {
+	workflow := `name: Deploy
+
+on: push
+
+env:
+  PROJECT_ID: my-project
+
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: gcloud run deploy --project ${{ env.PROJECT_ID }} --region "$REGION"
+        env:
+          REGION: ${{ env.REGION }}
+`
+
+	cases := []struct {
+		name         string
+		variables    []PropertiesVariable
+		checkAll     bool
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			name:         "declared_used",
+			variables:    []PropertiesVariable{{Name: "PROJECT_ID"}, {Name: "REGION"}},
+			wantErrors:   []string{},
+			wantWarnings: []string{},
+		},
+		{
+			name:         "declared_unused",
+			variables:    []PropertiesVariable{{Name: "PROJECT_ID"}, {Name: "REGION"}, {Name: "SERVICE"}},
+			wantErrors:   []string{"variable SERVICE is not referenced as env.SERVICE in workflow.yml"},
+			wantWarnings: []string{},
+		},
+		{
+			name:         "undeclared_used",
+			variables:    []PropertiesVariable{{Name: "PROJECT_ID"}},
+			wantErrors:   []string{},
+			wantWarnings: []string{"variable REGION is referenced in workflow.yml but not declared in the properties variables"},
+		},
+		{
+			// workflows without declared variables are only checked when asked to
+			name:         "none_declared",
+			wantErrors:   []string{},
+			wantWarnings: []string{},
+		},
+		{
+			name:       "none_declared_check_all",
+			checkAll:   true,
+			wantErrors: []string{},
+			wantWarnings: []string{
+				"variable PROJECT_ID is referenced in workflow.yml but not declared in the properties variables",
+				"variable REGION is referenced in workflow.yml but not declared in the properties variables",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+			writeTestFiles(t, ".", map[string]string{"workflow.yml": workflow})
+
+			gotErrors := make([]string, 0)
+			for _, err := range validateVariables("workflow.yml", tc.variables) {
+				gotErrors = append(gotErrors, err.Error())
+			}
+			if !reflect.DeepEqual(gotErrors, tc.wantErrors) {
+				t.Errorf("validateVariables = %q, want %q", gotErrors, tc.wantErrors)
+			}
+
+			gotWarnings := make([]string, 0)
+			for _, err := range lintUndeclaredVariables("workflow.yml", tc.variables, tc.checkAll) {
+				gotWarnings = append(gotWarnings, err.Error())
+			}
+			if !reflect.DeepEqual(gotWarnings, tc.wantWarnings) {
+				t.Errorf("lintUndeclaredVariables = %q, want %q", gotWarnings, tc.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestValidateStarterProperties(t *testing.T) {
+	properties := PropertiesConfig{
+		Name:        "Deploy to Cloud Run",
+		Description: "Deploy an app to Cloud Run.",
+		Categories:  []string{"Cloud Run"},
+	}
+
+	cases := []struct {
+		name     string
+		starter  bool
+		iconName string
+		want     []string
+	}{
+		{
+			name:     "starter_with_icon",
+			starter:  true,
+			iconName: "google-cloud",
+			want:     []string{},
+		},
+		{
+			name:    "starter_without_icon",
+			starter: true,
+			want:    []string{"properties iconName must not be empty for a starter workflow"},
+		},
+		{
+			name:     "starter_blank_icon",
+			starter:  true,
+			iconName: "  ",
+			want:     []string{"properties iconName must not be empty for a starter workflow"},
+		},
+		{
+			name: "non_starter_without_icon",
+			want: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			p := properties
+			p.IconName = tc.iconName
+
+			got := make([]string, 0)
+			for _, err := range validateStarterProperties(Workflow{Starter: tc.starter}, p) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("validateStarterProperties = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// stubActionlint puts an actionlint script running script on an otherwise empty PATH for the rest of
+// the test, or leaves actionlint off the PATH when script is empty
+func stubActionlint(t *testing.T, script string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if script != "" {
+		if _, err := os.Stat("/bin/sh"); err != nil {
+			t.Skipf("the actionlint stub needs /bin/sh: %s", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "actionlint"), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestValidateActionlint(t *testing.T) {
+	cases := []struct {
+		name     string
+		script   string
+		want     []string
+		wantLogs string
+	}{
+		{
+			name:   "findings",
+			script: `echo '[{"message":"label \"ubuntu-lates\" is unknown","line":9,"column":14,"kind":"runner-label"}]'; exit 1`,
+			want: []string{
+				`actionlint found a problem in workflows/deploy-appengine/appengine.yml at line 9: label "ubuntu-lates" is unknown [runner-label]`,
+				`actionlint found a problem in workflows/deploy-cloudrun/cloudrun-docker.yml at line 9: label "ubuntu-lates" is unknown [runner-label]`,
+				`actionlint found a problem in workflows/deploy-cloudrun/cloudrun-source.yml at line 9: label "ubuntu-lates" is unknown [runner-label]`,
+			},
+		},
+		{
+			name:   "clean",
+			script: "exit 0",
+			want:   []string{},
+		},
+		{
+			name:   "failed",
+			script: "exit 2",
+			want: []string{
+				"failed to run actionlint on workflows/deploy-appengine/appengine.yml: exit status 2",
+				"failed to run actionlint on workflows/deploy-cloudrun/cloudrun-docker.yml: exit status 2",
+				"failed to run actionlint on workflows/deploy-cloudrun/cloudrun-source.yml: exit status 2",
+			},
+		},
+		{
+			name:     "not_found",
+			want:     []string{},
+			wantLogs: "warning: skipping actionlint, it was not found on PATH: exec: \"actionlint\": executable file not found in $PATH\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			chdir(t, newTestRepo(t))
+			stubActionlint(t, tc.script)
+
+			var logs bytes.Buffer
+			g := NewGenerator(Options{Actionlint: true, Logger: NewLogger(&logs, LevelInfo)})
+			_, collector, err := g.Validate()
+			if err != nil {
+				t.Fatalf("Validate: %s", err)
+			}
+
+			got := make([]string, 0)
+			for _, problem := range collector.Problems {
+				if strings.Contains(problem.Err.Error(), "actionlint") {
+					got = append(got, problem.Err.Error())
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Validate reported actionlint problems %q, want %q", got, tc.want)
+			}
+			if got := logs.String(); got != tc.wantLogs {
+				t.Errorf("Validate logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestValidateCaseInsensitiveIDs(t *testing.T) {
+	cases := []struct {
+		name string
+		wc   WorkflowConfig
+		want []string
+	}{
+		{
+			name: "clean",
+			wc: WorkflowConfig{
+				"deploy":       {Starter: true, Type: "deployments", WorkflowPath: "workflows/deploy-cloudrun/deploy.yml"},
+				"deploy-other": {Starter: true, Type: "deployments", WorkflowPath: "workflows/deploy-cloudrun/deploy-other.yml"},
+				// the same file name released to another type directory does not collide
+				"deploy-ci": {Starter: true, Type: "ci", WorkflowPath: "workflows/deploy-ci/Deploy.yml"},
+			},
+			want: []string{},
+		},
+		{
+			name: "id_case_only",
+			wc: WorkflowConfig{
+				"Deploy": {Type: "deployments", WorkflowPath: "workflows/deploy-cloudrun/Deploy.yml"},
+				"deploy": {Type: "deployments", WorkflowPath: "workflows/deploy-cloudrun/deploy.yml"},
+			},
+			want: []string{"workflow IDs Deploy, deploy only differ in case and collide on case-insensitive filesystems"},
+		},
+		{
+			name: "release_file_case_only",
+			wc: WorkflowConfig{
+				"deploy-app":   {Starter: true, Type: "deployments", WorkflowPath: "workflows/deploy-cloudrun/Deploy.yml"},
+				"deploy-other": {Starter: true, Type: "deployments", WorkflowPath: "workflows/deploy-gke/deploy.yml"},
+			},
+			want: []string{"starter workflows deploy-app, deploy-other are released to files that only differ in case and collide on case-insensitive filesystems"},
+		},
+		{
+			// only starter workflows are released
+			name: "release_file_not_starter",
+			wc: WorkflowConfig{
+				"deploy-app":   {Starter: true, Type: "deployments", WorkflowPath: "workflows/deploy-cloudrun/Deploy.yml"},
+				"deploy-other": {Type: "deployments", WorkflowPath: "workflows/deploy-gke/deploy.yml"},
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, err := range validateCaseInsensitiveIDs(tc.wc) {
+				got = append(got, err.Error())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("validateCaseInsensitiveIDs = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package examples
+
+import (
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// yamlErrorLinePattern matches the line of a YAML parse error, e.g. yaml: line 3: mapping values are not allowed
+	yamlErrorLinePattern = regexp.MustCompile(`\bline (\d+):`)
+)
+
+// parseYAML parses a YAML document, returning its root node or nil for an empty document
+func parseYAML(content []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return doc.Content[0], nil
+}
+
+// yamlErrorLine returns the line of the first problem reported by a YAML parse error, or 0 when it has none
+func yamlErrorLine(err error) int {
+	match := yamlErrorLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	return line
+}
+
+// yamlGet returns the value for key when n is a mapping, otherwise nil
+func yamlGet(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return yamlResolve(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// yamlKeys returns the keys of a mapping node in document order
+func yamlKeys(n *yaml.Node) []string {
+	keys := make([]string, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keys = append(keys, n.Content[i].Value)
+	}
+	return keys
+}
+
+// yamlScalars returns every scalar value of n in document order, without the mapping keys
+func yamlScalars(n *yaml.Node) []*yaml.Node {
+	n = yamlResolve(n)
+	if n == nil {
+		return nil
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		scalars := make([]*yaml.Node, 0)
+		for i := 1; i < len(n.Content); i += 2 {
+			scalars = append(scalars, yamlScalars(n.Content[i])...)
+		}
+		return scalars
+	case yaml.SequenceNode:
+		scalars := make([]*yaml.Node, 0)
+		for _, item := range n.Content {
+			scalars = append(scalars, yamlScalars(item)...)
+		}
+		return scalars
+	case yaml.ScalarNode:
+		return []*yaml.Node{n}
+	default:
+		return nil
+	}
+}
+
+// yamlResolve returns the node an alias refers to, or the node itself when it is not an alias
+func yamlResolve(n *yaml.Node) *yaml.Node {
+	if n != nil && n.Kind == yaml.AliasNode {
+		return n.Alias
+	}
+	return n
+}
@@ -12,37 +12,90 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+//go:build ignore
+
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"html/template"
+	"io/fs"
+	"net/url"
 	"os"
 	"os/signal"
 	"path"
-	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
-)
+	"text/tabwriter"
 
-const (
-	readmeTitle              = "Google GitHub Actions - Example Workflows"
-	propertiesDirName string = "properties"
+	"github.com/google-github-actions/example-workflows/pkg/examples"
 )
 
 var (
 	starterPtr = flag.Bool("starter", false, "starter workflow")
 	typePtr    = flag.String("type", "deployments", "starter workflow type")
+	formatPtr  = flag.String("format", "json", "format of new properties files, json or yaml")
+	diffPtr    = flag.Bool("diff", false, "print the changes to the workflow config")
+	forcePtr   = flag.Bool("force", false, "overwrite an existing workflow, its properties file and its config entry")
+	dryRunPtr  = flag.Bool("dry-run", false, "print the files that would be created without writing them")
+	fromPtr    = flag.String("from", "", "existing workflow ID to copy the new workflow and properties from")
+	jsonPtr    = flag.Bool("json", false, "print output as JSON")
+	checkPtr   = flag.Bool("check", false, "check the README is up to date without writing it")
+	verbosePtr = flag.Bool("verbose", false, "log each processing step to stderr")
+	strictPtr  = flag.Bool("strict", false, "treat validation warnings as errors")
+	sincePtr   = flag.String("since", "", "only validate workflows changed since the given git ref")
+
+	onlyChangedPtr = flag.String("only-changed", "", "only validate workflows changed since the given base git ref, an alias of --since")
+
+	fixPtr = flag.Bool("fix", false, "rename properties files not named after their workflow ID and sort their categories before validating")
+
+	failOnWarningPtr = flag.Bool("fail-on-warning", false, "report validation warnings as warnings but exit non-zero when any are found")
+
+	checkLinksPtr = flag.Bool("check-links", false, "also check that the relative links in action READMEs point at existing files")
+
+	actionlintPtr = flag.Bool("actionlint", false, "also run actionlint on each workflow file and report its findings as validation errors")
+
+	checkTriggersPtr = flag.Bool("check-triggers", false, "fail validation when a workflow uses an 'on' trigger outside the allowed triggers")
+
+	checkVariablesPtr = flag.Bool("check-variables", false, "also warn about env variables referenced by workflows that declare no variables in their properties")
+
+	incrementalPtr = flag.Bool("incremental", false, "only rewrite the READMEs whose rendered content differs from the file on disk")
+
+	regenerateActionReadmesPtr = flag.Bool("regenerate-action-readmes", false, "render every action README from the action README template, overwriting existing content")
+
+	splitPtr = flag.Bool("split", false, "render each action into its action README and only link to the action READMEs from the README")
+
+	starterFirstPtr = flag.Bool("starter-first", false, "list starter workflows before other workflows of an action in the README")
+
+	workersPtr = flag.Int("workers", runtime.NumCPU(), "maximum number of properties files loaded or READMEs rendered concurrently")
+
+	maxNameLengthPtr = flag.Int("max-name-length", examples.DefaultMaxNameLength, "maximum length of a properties name, longer names are truncated by the starter workflows gallery")
+
+	creatorPtr    = flag.String("creator", "Google Cloud", "creator of the new workflow properties")
+	categoriesPtr = flag.String("categories", "", "comma separated categories of the new workflow properties, defaults to a category based on type")
 
-	propertiesTemplPath string = path.Join("templates", "workflow.properties.tmpl.json")
-	rootWorkflowPath    string = path.Join("workflows")
-	workflowConfigPath  string = path.Join("workflow.config.json")
-	readmeTmplatePath   string = path.Join("templates", "README.tmpl.md")
-	readmeOutputPath    string = path.Join(defaultEnv("OUTPUT_PATH", "README.md"))
+	configPtr        = flag.String("config", examples.DefaultConfigPath, "path to the workflow config")
+	workflowsDirPtr  = flag.String("workflows-dir", examples.DefaultWorkflowsDir, "directory new workflows are created in")
+	propertiesDirPtr = flag.String("properties-dir", examples.DefaultPropertiesDir, "directory new properties files are created in")
+	templateDirPtr   = flag.String("template-dir", defaultEnv("TEMPLATE_DIR", examples.DefaultTemplateDir), "directory of the README, action README and properties templates")
+
+	propertiesTemplPath string = path.Join(examples.DefaultTemplateDir, "workflow.properties.tmpl.json")
+
+	// githubOwnerPattern matches a GitHub user name or an org/team name
+	githubOwnerPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:/[A-Za-z0-9_.-]+)?$`)
+
+	// jsonCategoriesPattern matches the categories array of a JSON properties file, with the key as the first group
+	jsonCategoriesPattern = regexp.MustCompile(`("categories"\s*:\s*)\[[^\]]*\]`)
+	// yamlCategoriesPattern matches the categories block sequence of a YAML properties file
+	yamlCategoriesPattern = regexp.MustCompile(`(?m)^categories:[ \t]*\n(?:[ \t]+-[^\n]*(?:\n|$))+`)
 )
 
 func main() {
@@ -50,324 +103,1575 @@ func main() {
 	defer cancel()
 
 	flag.Parse()
+	args := parseCommandFlags(flag.Args())
+
+	level := examples.LevelInfo
+	if *verbosePtr {
+		level = examples.LevelDebug
+	}
 
-	if err := realMain(ctx); err != nil {
+	logger := examples.NewLogger(os.Stderr, level)
+	if err := realMain(ctx, logger, args); err != nil {
 		cancel()
-		fmt.Fprintf(os.Stderr, "%s\n", err)
+		logger.Errorf("%s", err)
 		os.Exit(1)
 	}
 }
 
-func realMain(ctx context.Context) error {
-	args := flag.Args()
+// parseCommandFlags parses the flags passed after the command, e.g. workflow --starter action-name/workflow-name,
+// and returns the command followed by its arguments
+func parseCommandFlags(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+
+	// the flags are parsed with flag.ExitOnError, so parsing exits on invalid flags
+	_ = flag.CommandLine.Parse(args[1:])
+	return append([]string{args[0]}, flag.Args()...)
+}
+
+func realMain(ctx context.Context, logger examples.Logger, args []string) error {
 	if len(args) <= 0 {
-		return fmt.Errorf("expected command workflow or readme, got none")
+		return fmt.Errorf("expected command workflow, new-action, delete, rename, move-action, set, list, stats, codeowners, readme, action-readmes, json-ld, validate, validate-properties, doctor, check-all or fmt-config, got none")
 	}
 
 	command := args[0]
 
+	if commandUsesWorkers(command) && *workersPtr < 1 {
+		return fmt.Errorf("--workers must be at least 1, got %d", *workersPtr)
+	}
+
+	// only the commands rendering READMEs embed the previews, so the others ignore an invalid value
+	previewLines := 0
+	if commandRendersReadmes(command) {
+		var err error
+		previewLines, err = strconv.Atoi(defaultEnv("EMBED_PREVIEW_LINES", "0"))
+		if err != nil || previewLines < 0 {
+			return fmt.Errorf("invalid EMBED_PREVIEW_LINES %s, should be 0 or a positive number of lines", os.Getenv("EMBED_PREVIEW_LINES"))
+		}
+	}
+
+	// --only-changed is an alias of --since, for the pull request checks
+	since := *sincePtr
+	if *onlyChangedPtr != "" {
+		if since != "" && since != *onlyChangedPtr {
+			return fmt.Errorf("--since %s and --only-changed %s must not both be set", since, *onlyChangedPtr)
+		}
+		since = *onlyChangedPtr
+	}
+
+	opts := examples.Options{
+		ConfigPath:              path.Clean(*configPtr),
+		WorkflowsDir:            path.Clean(*workflowsDirPtr),
+		PropertiesDir:           path.Clean(*propertiesDirPtr),
+		TemplateDir:             path.Clean(*templateDirPtr),
+		OutputFormat:            defaultEnv("OUTPUT_FORMAT", "md"),
+		ReadmePath:              os.Getenv("OUTPUT_PATH"),
+		ManifestPath:            os.Getenv("MANIFEST_PATH"),
+		LinkBaseURL:             os.Getenv("LINK_BASE_URL"),
+		PreviewLines:            previewLines,
+		IconsDir:                os.Getenv("ICONS_DIR"),
+		Check:                   *checkPtr,
+		CheckLinks:              *checkLinksPtr,
+		CheckTriggers:           *checkTriggersPtr,
+		CheckVariables:          *checkVariablesPtr,
+		Incremental:             *incrementalPtr,
+		RegenerateActionReadmes: *regenerateActionReadmesPtr,
+		Split:                   *splitPtr,
+		StarterFirst:            *starterFirstPtr,
+		Since:                   since,
+		Strict:                  *strictPtr,
+		FailOnWarning:           *failOnWarningPtr,
+		Actionlint:              *actionlintPtr,
+		MaxNameLength:           *maxNameLengthPtr,
+		Workers:                 *workersPtr,
+		Logger:                  logger,
+	}
+	if !isFlagSet("config") {
+		opts.ConfigPath = examples.DetectWorkflowConfigPath(opts.ConfigPath)
+	}
+	resolveConfigRelativePaths(&opts)
+
+	g := examples.NewGenerator(opts)
+	propertiesTemplPath = path.Join(g.TemplateDir, fmt.Sprintf("workflow.properties.tmpl.%s", *formatPtr))
+
+	if templatePaths := commandTemplatePaths(command, args, g); len(templatePaths) > 0 {
+		if err := validateTemplateDir(g.TemplateDir, templatePaths); err != nil {
+			return err
+		}
+	}
+
 	if strings.EqualFold(command, "workflow") {
-		return generateWorkflow(ctx, args)
+		return generateWorkflow(ctx, args, g)
+	}
+
+	if strings.EqualFold(command, "new-action") {
+		return newAction(ctx, args, g)
+	}
+
+	if strings.EqualFold(command, "delete") {
+		return deleteWorkflow(ctx, args, g)
+	}
+
+	if strings.EqualFold(command, "rename") {
+		return renameWorkflow(ctx, args, g)
+	}
+
+	if strings.EqualFold(command, "move-action") {
+		return moveAction(ctx, args, g)
+	}
+
+	if strings.EqualFold(command, "set") {
+		return setWorkflowField(ctx, args, g)
+	}
+
+	if strings.EqualFold(command, "list") {
+		return listWorkflows(ctx, g)
+	}
+
+	if strings.EqualFold(command, "stats") {
+		return printStats(ctx, g)
+	}
+
+	if strings.EqualFold(command, "codeowners") {
+		return printCodeowners(ctx, g)
 	}
 
 	if strings.EqualFold(command, "readme") {
-		return generateReadme(ctx)
+		return generateReadme(ctx, g)
+	}
+
+	if strings.EqualFold(command, "action-readmes") {
+		return generateActionReadmes(ctx, g)
+	}
+
+	if strings.EqualFold(command, "json-ld") {
+		return printJSONLD(ctx, g)
+	}
+
+	if strings.EqualFold(command, "validate") {
+		return validateWorkflows(ctx, g)
+	}
+
+	if strings.EqualFold(command, "validate-properties") {
+		return validatePropertiesFiles(ctx, args, g)
+	}
+
+	if strings.EqualFold(command, "doctor") {
+		return diagnoseWorkflows(ctx, g)
+	}
+
+	if strings.EqualFold(command, "check-all") {
+		return checkAll(ctx, g)
+	}
+
+	if strings.EqualFold(command, "fmt-config") {
+		return formatWorkflowConfig(ctx, g)
 	}
 
 	return fmt.Errorf("invalid command: %s", command)
 }
 
+// commandTemplatePaths returns the templates a command renders, which must exist in the template directory
+func commandTemplatePaths(command string, args []string, g *examples.Generator) []string {
+	readmeTemplatePath := path.Join(g.TemplateDir, fmt.Sprintf("README.tmpl.%s", g.OutputFormat))
+	actionReadmeTemplatePath := path.Join(g.TemplateDir, "action-README.tmpl.md")
+
+	switch strings.ToLower(command) {
+	case "workflow":
+		return []string{propertiesTemplPath, actionReadmeTemplatePath}
+	case "new-action":
+		// the first workflow of the action is only scaffolded when its name is passed
+		if len(args) == 3 {
+			return []string{propertiesTemplPath, actionReadmeTemplatePath}
+		}
+		return []string{actionReadmeTemplatePath}
+	case "readme", "check-all":
+		if g.Split || g.RegenerateActionReadmes {
+			return []string{readmeTemplatePath, actionReadmeTemplatePath}
+		}
+		return []string{readmeTemplatePath}
+	case "action-readmes":
+		return []string{actionReadmeTemplatePath}
+	}
+
+	return nil
+}
+
+// commandRendersReadmes reports whether a command renders the README or action READMEs, which embed
+// EMBED_PREVIEW_LINES lines of each workflow
+func commandRendersReadmes(command string) bool {
+	switch strings.ToLower(command) {
+	case "workflow", "new-action", "move-action", "readme", "action-readmes", "json-ld", "check-all":
+		return true
+	}
+
+	return false
+}
+
+// commandUsesWorkers reports whether a command loads properties files or renders READMEs with --workers
+// concurrent workers
+func commandUsesWorkers(command string) bool {
+	switch strings.ToLower(command) {
+	case "readme", "action-readmes", "json-ld", "check-all", "codeowners":
+		return true
+	}
+
+	return false
+}
+
+// validateTemplateDir checks that the template directory exists and contains every template, listing the missing ones
+func validateTemplateDir(templateDir string, templatePaths []string) error {
+	if info, err := os.Stat(templateDir); err != nil {
+		return fmt.Errorf("failed to find template directory %s: %w", templateDir, err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("template directory %s is not a directory", templateDir)
+	}
+
+	missing := make([]string, 0)
+	for _, templatePath := range templatePaths {
+		if _, err := os.Stat(templatePath); os.IsNotExist(err) {
+			missing = append(missing, path.Base(templatePath))
+		} else if err != nil {
+			return fmt.Errorf("failed to validate %s exists: %w", templatePath, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("template directory %s is missing required templates: %s", templateDir, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// resolveConfigRelativePaths resolves the default directories and README relative to the directory of a
+// --config outside the working directory, leaving the flags and OUTPUT_PATH that are set untouched. The
+// paths in the config itself are resolved against its directory by LoadWorkflowConfig.
+func resolveConfigRelativePaths(opts *examples.Options) {
+	configDir := path.Dir(opts.ConfigPath)
+	if configDir == "." {
+		return
+	}
+
+	if !isFlagSet("workflows-dir") {
+		opts.WorkflowsDir = path.Join(configDir, opts.WorkflowsDir)
+	}
+
+	if !isFlagSet("properties-dir") {
+		opts.PropertiesDir = path.Join(configDir, opts.PropertiesDir)
+	}
+
+	if _, ok := os.LookupEnv("OUTPUT_PATH"); !ok {
+		opts.ReadmePath = path.Join(configDir, fmt.Sprintf("README.%s", defaultEnv("OUTPUT_FORMAT", "md")))
+	}
+}
+
 // generateWorkflow handles the creation of new workflow files
-func generateWorkflow(ctx context.Context, args []string) error {
+func generateWorkflow(ctx context.Context, args []string, g *examples.Generator) error {
 	if len(args) != 2 {
 		return fmt.Errorf("expected 2 arguments, got %d: %q", len(args), args)
 	}
 
-	var wc workflowConfig
-	if err := loadJSONFromFile(&wc, workflowConfigPath); err != nil {
+	if !examples.IsValidType(*typePtr) {
+		return fmt.Errorf("invalid type %q, expected one of %s, optionally followed by /subdirectory", *typePtr, strings.Join(examples.SortedTypes(), ", "))
+	}
+
+	if *formatPtr != "json" && *formatPtr != "yaml" {
+		return fmt.Errorf("invalid format %q, expected json or yaml", *formatPtr)
+	}
+
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
 		return fmt.Errorf("failed to load workflow config: %w", err)
 	}
 
-	workflowArg := args[1]
+	workflowArg := path.Clean(args[1])
 	workflowID := path.Base(workflowArg)
-	workflowDir := path.Join(rootWorkflowPath, path.Dir(workflowArg))
+	workflowDir := path.Join(g.WorkflowsDir, path.Dir(workflowArg))
 	workflowFilePath := path.Join(workflowDir, fmt.Sprintf("%s.yml", workflowID))
-	workflowDirParts := strings.Split(workflowDir, "/")
 
-	// This should be at least workflows/action-name, but can be longer
-	if len(workflowDirParts) < 2 {
+	// This should be at least action-name/workflow-name, but can be longer
+	if path.Dir(workflowArg) == "." {
 		return fmt.Errorf("invalid workflow path %s, path should have at least 2 folders, e.g. action-name/workflow-name", workflowDir)
 	}
 
-	actionName := workflowDirParts[1]
-	actionPath := path.Join(workflowDirParts[:2]...)
+	actionName := strings.Split(workflowArg, "/")[0]
+	actionPath := path.Join(g.WorkflowsDir, actionName)
 	actionReadMePath := path.Join(actionPath, "README.md")
 
 	if _, ok := wc[workflowID]; ok {
-		return fmt.Errorf("workflow exists in %s, please use existing workflow or use a different name", workflowConfigPath)
+		if !*forcePtr {
+			return fmt.Errorf("workflow exists in %s, please use existing workflow or use a different name", g.ConfigPath)
+		}
+		g.Logger.Warnf("replacing the entry for workflow %s in %s", workflowID, g.ConfigPath)
 	}
 
+	workflowFileExists := false
 	if _, err := os.Stat(workflowFilePath); err == nil {
-		return fmt.Errorf("workflow file %s already exists", workflowFilePath)
+		if !*forcePtr {
+			return fmt.Errorf("workflow file %s already exists", workflowFilePath)
+		}
+		workflowFileExists = true
+		g.Logger.Warnf("overwriting workflow file %s", workflowFilePath)
 	}
 
-	if err := os.MkdirAll(workflowDir, 0755); err != nil {
-		return fmt.Errorf("failed to create workflow directory: %w", err)
-	}
+	propertiesFilePath := path.Join(g.PropertiesDir, fmt.Sprintf("%s.properties.%s", workflowID, *formatPtr))
 
-	_, err := os.Stat(actionReadMePath)
-	if os.IsNotExist(err) {
-		actionReadMeContents := fmt.Sprintf("# %s examples", actionName)
-		if err := os.WriteFile(actionReadMePath, []byte(actionReadMeContents), 0644); err != nil {
-			return fmt.Errorf("failed writing content to action README file %s: %w", actionReadMePath, err)
+	for _, filePath := range []string{workflowFilePath, propertiesFilePath} {
+		if err := g.ValidateConfigPath(filePath); err != nil {
+			return fmt.Errorf("invalid path for workflow %s: %w", workflowID, err)
 		}
-	} else if err != nil {
-		return fmt.Errorf("failed to validate %s exists: %w", actionReadMePath, err)
 	}
 
-	fileContents := "# TODO: Add meaningful workflow content here."
-	if err := os.WriteFile(workflowFilePath, []byte(fileContents), 0644); err != nil {
-		return fmt.Errorf("writing content to workflow file: %w", err)
+	propertiesFileExists := false
+	if _, err := os.Stat(propertiesFilePath); err == nil && *forcePtr {
+		propertiesFileExists = true
+		g.Logger.Warnf("overwriting properties file %s", propertiesFilePath)
 	}
 
-	propertiesFilePath := path.Join(propertiesDirName, fmt.Sprintf("%s.properties.json", workflowID))
-	propertiesConfig := &propertiesTemplateConfig{
-		WorkflowID: workflowID,
+	workflowContents := []byte(examples.WorkflowStubContents)
+	var fromProperties *examples.PropertiesConfig
+	if *fromPtr != "" {
+		fromWorkflow, ok := wc[*fromPtr]
+		if !ok {
+			return fmt.Errorf("workflow %s to copy from does not exist in %s", *fromPtr, g.ConfigPath)
+		}
+
+		contents, err := os.ReadFile(fromWorkflow.WorkflowPath)
+		if err != nil {
+			return fmt.Errorf("failed to read workflow file to copy from: %w", err)
+		}
+		workflowContents = contents
+
+		fromProperties = &examples.PropertiesConfig{}
+		if err := examples.LoadConfigFile(fromProperties, fromWorkflow.PropertiesPath); err != nil {
+			return fmt.Errorf("failed to load properties file to copy from %s: %w", fromWorkflow.PropertiesPath, err)
+		}
 	}
 
-	if err := renderTemplate(propertiesTemplPath, propertiesFilePath, propertiesConfig); err != nil {
-		return fmt.Errorf("failed to render properties template: %w", err)
+	createActionReadMe, err := examples.IsActionReadmeMissing(actionReadMePath)
+	if err != nil {
+		return err
 	}
 
-	wc[workflowID] = workflow{
+	newWorkflow := examples.Workflow{
 		Starter:        *starterPtr,
 		Type:           *typePtr,
 		WorkflowPath:   workflowFilePath,
 		PropertiesPath: propertiesFilePath,
 	}
 
-	newConfigBytes, err := json.MarshalIndent(wc, "", "  ")
-	if err != nil {
-		return fmt.Errorf("fail to marshal new workflow config: %w", err)
+	if *dryRunPtr {
+		if createActionReadMe {
+			fmt.Printf("would create: %s\n", actionReadMePath)
+		}
+		fmt.Printf("%s %s\n", dryRunAction(workflowFileExists), workflowFilePath)
+		fmt.Printf("%s %s\n", dryRunAction(propertiesFileExists), propertiesFilePath)
+		fmt.Printf("would update: %s\n", g.ConfigPath)
+
+		if *diffPtr {
+			wc[workflowID] = newWorkflow
+			return printConfigDiff(g, wc)
+		}
+		return nil
+	}
+
+	for _, filePath := range []string{workflowFilePath, propertiesFilePath} {
+		if err := examples.MkdirParent(filePath); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(workflowFilePath, workflowContents, 0644); err != nil {
+		return fmt.Errorf("writing content to workflow file: %w", err)
+	}
+
+	if fromProperties != nil {
+		if err := writeClonedProperties(*fromProperties, workflowID, propertiesFilePath); err != nil {
+			return err
+		}
+	} else if err := renderProperties(workflowID, propertiesFilePath); err != nil {
+		return err
+	}
+
+	wc[workflowID] = newWorkflow
+
+	if *diffPtr {
+		if err := printConfigDiff(g, wc); err != nil {
+			return err
+		}
+	}
+
+	if err := g.WriteWorkflowConfig(wc); err != nil {
+		return err
 	}
 
-	if err := os.WriteFile(workflowConfigPath, newConfigBytes, 0644); err != nil {
-		return fmt.Errorf("failed to write update workflow config: %w", err)
+	if err := g.EnsureActionReadme(wc, actionName, actionPath); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// generateWorkflow handles the creation of the main readme and individual action readmes
-func generateReadme(ctx context.Context) error {
-	var wfConfig workflowConfig
-	if err := loadJSONFromFile(&wfConfig, workflowConfigPath); err != nil {
-		return fmt.Errorf("failed to load workflow config %s: %w", workflowConfigPath, err)
+// dryRunAction describes what a dry run would do to a file
+func dryRunAction(exists bool) string {
+	if exists {
+		return "would overwrite:"
+	}
+	return "would create:"
+}
+
+// newAction handles the creation of a new action folder with its README, and optionally its first workflow
+func newAction(ctx context.Context, args []string, g *examples.Generator) error {
+	if len(args) != 2 && len(args) != 3 {
+		return fmt.Errorf("expected 2 or 3 arguments, got %d: %q", len(args), args)
 	}
 
-	hasInvalidConfigs := false
-	sortedWorkflowsIDs := getSortedWorkflowIDs(wfConfig)
-	readmeActions := map[string]readmeAction{}
+	actionName := path.Clean(args[1])
+	if strings.Contains(actionName, "/") || actionName == "." || actionName == ".." {
+		return fmt.Errorf("invalid action name %s, should be a single folder name, e.g. action-name", actionName)
+	}
 
-	for _, workflowID := range sortedWorkflowsIDs {
-		workflow := wfConfig[workflowID]
-		workflowPathParts := strings.Split(workflow.WorkflowPath, "/")
+	actionPath := path.Join(g.WorkflowsDir, actionName)
+	if _, err := os.Stat(actionPath); err == nil {
+		return fmt.Errorf("action %s already exists: %s", actionName, actionPath)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to validate %s exists: %w", actionPath, err)
+	}
 
-		// This should be at least workflows/action-name/workflow-name.yml, but can be longer
-		if len(workflowPathParts) < 3 {
-			return fmt.Errorf("invalid workflow path %s, should be at least workflows/action-name/workflow-name.yml", workflow.WorkflowPath)
+	// the first workflow creates the action folder and its README along with the workflow
+	if len(args) == 3 {
+		workflowName := path.Clean(args[2])
+		if strings.Contains(workflowName, "/") || workflowName == "." || workflowName == ".." {
+			return fmt.Errorf("invalid workflow name %s, should be a single file name without extension, e.g. workflow-name", workflowName)
 		}
+		return generateWorkflow(ctx, []string{"workflow", path.Join(actionName, workflowName)}, g)
+	}
 
-		actionName := workflowPathParts[1]
-		actionPath := path.Join(workflowPathParts[:2]...)
-		actionReadMePath := path.Join(actionPath, "README.md")
-		workflowSubPath := path.Join(workflowPathParts[2:]...)
-		workflowRelativeName := strings.TrimSuffix(workflowSubPath, filepath.Ext(workflowSubPath))
+	if *dryRunPtr {
+		fmt.Printf("would create: %s\n", path.Join(actionPath, "README.md"))
+		return nil
+	}
 
-		if err := validateGenerateReadme(workflow, readmeAction{ReadMePath: actionReadMePath}); err != nil {
-			fmt.Println(fmt.Errorf("validation failed for generate readme workflow %s: %w", workflowID, err))
-			hasInvalidConfigs = true
-			continue
-		}
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
+		return fmt.Errorf("failed to load workflow config: %w", err)
+	}
 
-		var properties propertiesConfig
-		if err := loadJSONFromFile(&properties, workflow.PropertiesPath); err != nil {
-			return fmt.Errorf("failed to load properties file %s: %w", workflow.PropertiesPath, err)
-		}
+	if err := os.MkdirAll(actionPath, 0755); err != nil {
+		return fmt.Errorf("failed to create action directory: %w", err)
+	}
 
-		actionData, hasKey := readmeActions[actionName]
-		if !hasKey {
-			emptyWorkflows := make([]readmeWorkflow, 0)
-			actionData = readmeAction{
-				Name:       actionName,
-				Path:       actionPath,
-				ReadMePath: actionReadMePath,
-				Workflows:  emptyWorkflows,
-			}
-		}
+	return g.EnsureActionReadme(wc, actionName, actionPath)
+}
 
-		actionData.Workflows = append(actionData.Workflows, readmeWorkflow{
-			Name:           properties.Name,
-			RelativeName:   workflowRelativeName,
-			Description:    properties.Description,
-			Starter:        workflow.Starter,
-			WorkflowPath:   workflow.WorkflowPath,
-			PropertiesPath: workflow.PropertiesPath,
-		})
+// deleteWorkflow handles the removal of a workflow, its files and its config entry
+func deleteWorkflow(ctx context.Context, args []string, g *examples.Generator) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected 2 arguments, got %d: %q", len(args), args)
+	}
+
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
+		return fmt.Errorf("failed to load workflow config: %w", err)
+	}
 
-		readmeActions[actionData.Name] = actionData
+	workflowID := path.Base(args[1])
+	workflow, ok := wc[workflowID]
+	if !ok {
+		return fmt.Errorf("workflow %s does not exist in %s", workflowID, g.ConfigPath)
 	}
 
-	if hasInvalidConfigs {
-		return fmt.Errorf("failed to process invalid configs")
+	for _, filePath := range []string{workflow.WorkflowPath, workflow.PropertiesPath} {
+		if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", filePath, err)
+		}
 	}
 
-	sortedActions := getSortedActionNames(readmeActions)
+	delete(wc, workflowID)
+
+	if err := g.WriteWorkflowConfig(wc); err != nil {
+		return err
+	}
 
-	readmeTemplateConfigs := readmeTemplateConfig{
-		Title:   readmeTitle,
-		Actions: sortedActions,
+	actionPath, err := g.ActionPathOf(workflow.WorkflowPath)
+	if err != nil {
+		actionPath = path.Dir(workflow.WorkflowPath)
 	}
 
-	if err := renderTemplate(readmeTmplatePath, readmeOutputPath, readmeTemplateConfigs); err != nil {
-		return fmt.Errorf("failed to render readme template: %w", err)
+	for _, w := range wc {
+		if strings.HasPrefix(w.WorkflowPath, actionPath+"/") {
+			return nil
+		}
 	}
 
+	g.Logger.Warnf("%s has no remaining workflows, consider removing %s", actionPath, path.Join(actionPath, "README.md"))
+
 	return nil
 }
 
-// validateGenerateReadme handles validations for generating readmes
-func validateGenerateReadme(w workflow, a readmeAction) error {
-	if _, err := os.Stat(w.WorkflowPath); err != nil {
-		return fmt.Errorf("failed to validate %s exists: %w", w.WorkflowPath, err)
+// renameWorkflow handles moving a workflow and its properties file to a new workflow path and ID
+func renameWorkflow(ctx context.Context, args []string, g *examples.Generator) error {
+	if len(args) != 3 {
+		return fmt.Errorf("expected 3 arguments, got %d: %q", len(args), args)
 	}
 
-	if _, err := os.Stat(w.PropertiesPath); err != nil {
-		return fmt.Errorf("failed to validate %s exists: %w", w.PropertiesPath, err)
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
+		return fmt.Errorf("failed to load workflow config: %w", err)
 	}
 
-	if _, err := os.Stat(a.ReadMePath); err != nil {
-		return fmt.Errorf("failed to validate %s exists: %w", a.ReadMePath, err)
+	oldID := path.Base(args[1])
+	oldWorkflow, ok := wc[oldID]
+	if !ok {
+		return fmt.Errorf("workflow %s does not exist in %s", oldID, g.ConfigPath)
 	}
 
-	return nil
-}
+	newArg := path.Clean(args[2])
+	newID := path.Base(newArg)
+	newWorkflowDir := path.Join(g.WorkflowsDir, path.Dir(newArg))
+	newWorkflowFilePath := path.Join(newWorkflowDir, fmt.Sprintf("%s.yml", newID))
+	newPropertiesFilePath := path.Join(g.PropertiesDir, newID+examples.PropertiesFileSuffix(oldWorkflow.PropertiesPath))
 
-// renderTemplate renders a go template
-func renderTemplate(templatePath string, outputPath string, templateConfig interface{}) error {
-	template, err := template.ParseFiles(templatePath)
-	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+	// This should be at least action-name/workflow-name, but can be longer
+	if path.Dir(newArg) == "." {
+		return fmt.Errorf("invalid workflow path %s, path should have at least 2 folders, e.g. action-name/workflow-name", newWorkflowDir)
 	}
 
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	if _, ok := wc[newID]; ok {
+		return fmt.Errorf("workflow %s already exists in %s, please use a different name", newID, g.ConfigPath)
 	}
-	defer file.Close()
 
-	err = template.Execute(file, templateConfig)
-	if err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+	for _, filePath := range []string{newWorkflowFilePath, newPropertiesFilePath} {
+		if _, err := os.Stat(filePath); err == nil {
+			return fmt.Errorf("file %s already exists", filePath)
+		}
+	}
+
+	for _, filePath := range []string{newWorkflowFilePath, newPropertiesFilePath} {
+		if err := examples.MkdirParent(filePath); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Rename(oldWorkflow.WorkflowPath, newWorkflowFilePath); err != nil {
+		return fmt.Errorf("failed to move workflow file: %w", err)
+	}
+
+	if err := os.Rename(oldWorkflow.PropertiesPath, newPropertiesFilePath); err != nil {
+		return rollbackRenames(fmt.Errorf("failed to move properties file: %w", err), [][2]string{
+			{oldWorkflow.WorkflowPath, newWorkflowFilePath},
+		})
+	}
+
+	delete(wc, oldID)
+	wc[newID] = examples.Workflow{
+		Starter:        oldWorkflow.Starter,
+		Type:           oldWorkflow.Type,
+		WorkflowPath:   newWorkflowFilePath,
+		PropertiesPath: newPropertiesFilePath,
+	}
+
+	if err := g.WriteWorkflowConfig(wc); err != nil {
+		return rollbackRenames(err, [][2]string{
+			{oldWorkflow.WorkflowPath, newWorkflowFilePath},
+			{oldWorkflow.PropertiesPath, newPropertiesFilePath},
+		})
 	}
 
 	return nil
 }
 
-// getSortedWorkflowIDs sorts workflowConfig by workflowID
-func getSortedWorkflowIDs(workflowConfig workflowConfig) []string {
-	workflowIDs := make([]string, 0, len(workflowConfig))
-	for id := range workflowConfig {
-		workflowIDs = append(workflowIDs, id)
+// rollbackRenames moves each renamed new path back to its old path after err, so a failed command
+// does not leave the files out of sync with the config, and returns err with any rollback failure
+func rollbackRenames(err error, renames [][2]string) error {
+	for i := len(renames) - 1; i >= 0; i-- {
+		oldPath, newPath := renames[i][0], renames[i][1]
+		if rollbackErr := os.Rename(newPath, oldPath); rollbackErr != nil {
+			return fmt.Errorf("%w, and failed to move %s back to %s: %s", err, newPath, oldPath, rollbackErr)
+		}
 	}
-	sort.Strings(workflowIDs)
-
-	return workflowIDs
+	return err
 }
 
-// getSortedActionNames sorts a list of readmeActions by name
-func getSortedActionNames(actions map[string]readmeAction) []readmeAction {
-	actionNames := make([]string, 0, len(actions))
-	for name := range actions {
-		actionNames = append(actionNames, name)
+// moveAction handles moving an action directory, with its workflows and README, to a new action name,
+// updating the workflow paths in the config and rendering the README for the new name. Properties files
+// are keyed by workflow ID and are not moved
+func moveAction(ctx context.Context, args []string, g *examples.Generator) error {
+	if len(args) != 3 {
+		return fmt.Errorf("expected 3 arguments, got %d: %q", len(args), args)
 	}
-	sort.Strings(actionNames)
 
-	readmeActionData := make([]readmeAction, 0, len(actions))
-	for _, actionName := range actionNames {
-		readmeActionData = append(readmeActionData, actions[actionName])
+	oldName, newName := path.Clean(args[1]), path.Clean(args[2])
+	for _, name := range []string{oldName, newName} {
+		if strings.Contains(name, "/") || name == "." || name == ".." {
+			return fmt.Errorf("invalid action name %s, should be a single folder name, e.g. action-name", name)
+		}
 	}
 
-	return readmeActionData
-}
+	oldActionPath := path.Join(g.WorkflowsDir, oldName)
+	newActionPath := path.Join(g.WorkflowsDir, newName)
 
-// loadJSONFromFile loads unmarshals json from a file path
-func loadJSONFromFile(config interface{}, path string) error {
-	configBytes, err := os.ReadFile(path)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+	if info, err := os.Stat(oldActionPath); err != nil {
+		return fmt.Errorf("action %s does not exist: %w", oldName, err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("action %s is not a directory: %s", oldName, oldActionPath)
+	}
+
+	if _, err := os.Stat(newActionPath); err == nil {
+		return fmt.Errorf("action %s already exists: %s", newName, newActionPath)
+	}
+
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
+		return fmt.Errorf("failed to load workflow config: %w", err)
+	}
+
+	moved := 0
+	for _, workflowID := range examples.SortedWorkflowIDs(wc) {
+		workflow := wc[workflowID]
+		if !strings.HasPrefix(workflow.WorkflowPath, oldActionPath+"/") {
+			continue
+		}
+
+		workflow.WorkflowPath = path.Join(newActionPath, strings.TrimPrefix(workflow.WorkflowPath, oldActionPath+"/"))
+		wc[workflowID] = workflow
+		moved++
+	}
+
+	if *diffPtr {
+		if err := printConfigDiff(g, wc); err != nil {
+			return err
+		}
 	}
 
-	if err := json.Unmarshal(configBytes, &config); err != nil {
-		return fmt.Errorf("failed to unmarshal: %w", err)
+	if *dryRunPtr {
+		fmt.Printf("would move: %s -> %s\n", oldActionPath, newActionPath)
+		fmt.Printf("would update: %s\n", g.ConfigPath)
+		fmt.Printf("would update: %s\n", path.Join(newActionPath, "README.md"))
+		return nil
+	}
+
+	if err := examples.MkdirParent(newActionPath); err != nil {
+		return err
+	}
+
+	if err := os.Rename(oldActionPath, newActionPath); err != nil {
+		return fmt.Errorf("failed to move action directory: %w", err)
+	}
+
+	if err := g.WriteWorkflowConfig(wc); err != nil {
+		return rollbackRenames(err, [][2]string{{oldActionPath, newActionPath}})
+	}
+	g.Logger.Infof("moved %s -> %s and updated %d workflow(s) in %s", oldActionPath, newActionPath, moved, g.ConfigPath)
+
+	// the heading and workflow links of the README name the action
+	if err := g.RenderActionReadme(wc, newName, newActionPath); err != nil {
+		return fmt.Errorf("failed to render the README of moved action %s: %w", newName, err)
 	}
 
 	return nil
 }
 
-// defaultEnv sets a default value for a missing environment variable
-func defaultEnv(key string, defaultValue string) string {
-	if value, ok := os.LookupEnv(key); ok {
-		return value
+// setWorkflowField sets a field of every workflow matching the optional workflow ID glob and the
+// type and starter flags when set
+func setWorkflowField(ctx context.Context, args []string, g *examples.Generator) error {
+	if len(args) != 3 && len(args) != 4 {
+		return fmt.Errorf("expected 3 or 4 arguments, got %d: %q", len(args), args)
 	}
-	return defaultValue
-}
 
-// propertiesTemplateConfig is the go template config used for the workflow properties template
-type propertiesTemplateConfig struct {
-	WorkflowID string
-}
+	field, value := args[1], args[2]
 
-// propertiesConfig are the object properties for the *.properties.json files
-type propertiesConfig struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Creator     string   `json:"creator"`
-	IconName    string   `json:"iconName"`
-	Categories  []string `json:"categories"`
-}
+	pattern := "*"
+	if len(args) == 4 {
+		pattern = args[3]
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid workflow pattern %q: %w", pattern, err)
+	}
+
+	var update func(w *examples.Workflow)
+	switch field {
+	case "starter":
+		starter, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid starter value %q, expected true or false", value)
+		}
+		update = func(w *examples.Workflow) { w.Starter = starter }
+	case "type":
+		if !examples.IsValidType(value) {
+			return fmt.Errorf("invalid type %q, expected one of %s, optionally followed by /subdirectory", value, strings.Join(examples.SortedTypes(), ", "))
+		}
+		update = func(w *examples.Workflow) { w.Type = value }
+	default:
+		return fmt.Errorf("invalid field %q, expected starter or type", field)
+	}
+
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
+		return fmt.Errorf("failed to load workflow config: %w", err)
+	}
+
+	changed := 0
+	for _, workflowID := range examples.SortedWorkflowIDs(wc) {
+		workflow := wc[workflowID]
+		if matched, _ := path.Match(pattern, workflowID); !matched {
+			continue
+		}
+		if isFlagSet("type") && workflow.Type != *typePtr {
+			continue
+		}
+		if isFlagSet("starter") && workflow.Starter != *starterPtr {
+			continue
+		}
 
-// workflow is the object properties for each workflow
-type workflow struct {
-	Starter        bool   `json:"starter"`
-	Type           string `json:"type"`
-	WorkflowPath   string `json:"workflowPath"`
-	PropertiesPath string `json:"propertiesPath"`
+		updated := workflow
+		update(&updated)
+		if updated == workflow {
+			continue
+		}
+
+		wc[workflowID] = updated
+		changed++
+		g.Logger.Infof("set %s=%s for workflow %s", field, value, workflowID)
+	}
+
+	if changed == 0 {
+		g.Logger.Infof("no workflows changed in %s", g.ConfigPath)
+		return nil
+	}
+
+	if *diffPtr {
+		if err := printConfigDiff(g, wc); err != nil {
+			return err
+		}
+	}
+
+	if *dryRunPtr {
+		fmt.Printf("would update: %s\n", g.ConfigPath)
+		return nil
+	}
+
+	return g.WriteWorkflowConfig(wc)
 }
 
-// workflowConfig is the object referencing all workflow configs
-type workflowConfig map[string]workflow
+// listWorkflows prints the workflows in the config, filtered by the type and starter flags when set
+func listWorkflows(ctx context.Context, g *examples.Generator) error {
+	var wfConfig examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wfConfig); err != nil {
+		return fmt.Errorf("failed to load workflow config %s: %w", g.ConfigPath, err)
+	}
+
+	filtered := examples.WorkflowConfig{}
+	for workflowID, workflow := range wfConfig {
+		if isFlagSet("type") && workflow.Type != *typePtr {
+			continue
+		}
+		if isFlagSet("starter") && workflow.Starter != *starterPtr {
+			continue
+		}
+		filtered[workflowID] = workflow
+	}
+
+	if *jsonPtr {
+		filteredBytes, err := json.MarshalIndent(filtered, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal workflows: %w", err)
+		}
+		fmt.Println(string(filteredBytes))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tSTARTER\tWORKFLOW PATH")
+	for _, workflowID := range examples.SortedWorkflowIDs(filtered) {
+		workflow := filtered[workflowID]
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", workflowID, workflow.Type, workflow.Starter, workflow.WorkflowPath)
+	}
+
+	return w.Flush()
+}
+
+// printStats prints the number of workflows per type, per category and by starter status
+func printStats(ctx context.Context, g *examples.Generator) error {
+	var wfConfig examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wfConfig); err != nil {
+		return fmt.Errorf("failed to load workflow config %s: %w", g.ConfigPath, err)
+	}
+
+	stats := workflowStats{
+		Types:      map[string]int{},
+		Categories: map[string]int{},
+	}
+
+	for _, workflowID := range examples.SortedWorkflowIDs(wfConfig) {
+		workflow := wfConfig[workflowID]
+
+		var properties examples.PropertiesConfig
+		if err := examples.LoadConfigFile(&properties, workflow.PropertiesPath); err != nil {
+			return fmt.Errorf("failed to load properties file %s: %w", workflow.PropertiesPath, err)
+		}
+
+		stats.Types[workflow.Type]++
+		for _, category := range properties.Categories {
+			stats.Categories[category]++
+		}
+
+		if workflow.Starter {
+			stats.Starters++
+		} else {
+			stats.NonStarters++
+		}
+	}
+
+	if *jsonPtr {
+		statsBytes, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal stats: %w", err)
+		}
+		fmt.Println(string(statsBytes))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tWORKFLOWS")
+	for _, workflowType := range examples.SortedKeys(stats.Types) {
+		fmt.Fprintf(w, "%s\t%d\n", workflowType, stats.Types[workflowType])
+	}
+
+	fmt.Fprintln(w, "\nCATEGORY\tWORKFLOWS")
+	for _, category := range examples.SortedKeys(stats.Categories) {
+		fmt.Fprintf(w, "%s\t%d\n", category, stats.Categories[category])
+	}
+
+	fmt.Fprintln(w, "\nSTARTER\tWORKFLOWS")
+	fmt.Fprintf(w, "true\t%d\n", stats.Starters)
+	fmt.Fprintf(w, "false\t%d\n", stats.NonStarters)
+
+	return w.Flush()
+}
+
+// printCodeowners prints a suggested CODEOWNERS line for each action directory, owned by the
+// creators of its workflows. Actions without a creator usable as an owner are printed commented out
+func printCodeowners(ctx context.Context, g *examples.Generator) error {
+	var wfConfig examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wfConfig); err != nil {
+		return fmt.Errorf("failed to load workflow config %s: %w", g.ConfigPath, err)
+	}
+
+	workflowIDs := examples.SortedWorkflowIDs(wfConfig)
+	loadedProperties := g.LoadPropertiesFiles(wfConfig, workflowIDs)
+
+	actionCreators := map[string]map[string]bool{}
+	for _, workflowID := range workflowIDs {
+		workflow := wfConfig[workflowID]
+
+		actionPath, err := g.ActionPathOf(workflow.WorkflowPath)
+		if err != nil {
+			return err
+		}
+		// CODEOWNERS paths are relative to the repository of the config
+		actionPath = g.ConfigRelativePath(actionPath)
+
+		loaded := loadedProperties[workflowID]
+		if loaded.Err != nil {
+			return fmt.Errorf("failed to load properties file %s: %w", workflow.PropertiesPath, loaded.Err)
+		}
+
+		if _, ok := actionCreators[actionPath]; !ok {
+			actionCreators[actionPath] = map[string]bool{}
+		}
+		if creator := strings.TrimSpace(loaded.Properties.Creator); creator != "" {
+			actionCreators[actionPath][creator] = true
+		}
+	}
+
+	for _, line := range codeownersLines(actionCreators, g) {
+		fmt.Println(line)
+	}
+
+	return nil
+}
+
+// codeownersLines returns the CODEOWNERS lines of each action path, sorted by path, with the sorted owners of
+// each action. Creators that are not a GitHub user, team or email address are left out with a warning
+func codeownersLines(actionCreators map[string]map[string]bool, g *examples.Generator) []string {
+	actionPaths := make([]string, 0, len(actionCreators))
+	for actionPath := range actionCreators {
+		actionPaths = append(actionPaths, actionPath)
+	}
+	sort.Strings(actionPaths)
+
+	lines := make([]string, 0, len(actionPaths))
+	for _, actionPath := range actionPaths {
+		creators := make([]string, 0, len(actionCreators[actionPath]))
+		for creator := range actionCreators[actionPath] {
+			creators = append(creators, creator)
+		}
+		sort.Strings(creators)
+
+		// creators written with and without the @ are the same owner
+		owners := make([]string, 0, len(creators))
+		seenOwners := map[string]bool{}
+		for _, creator := range creators {
+			owner, ok := codeownersOwner(creator)
+			if !ok {
+				g.Logger.Warnf("creator %q of %s is not a GitHub user, team or email address", creator, actionPath)
+				continue
+			}
+			if !seenOwners[owner] {
+				seenOwners[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+		sort.Strings(owners)
+
+		pattern := fmt.Sprintf("/%s/", actionPath)
+		if len(owners) == 0 {
+			lines = append(lines, fmt.Sprintf("# %s has no creator usable as an owner", pattern))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", pattern, strings.Join(owners, " ")))
+	}
+
+	return lines
+}
+
+// codeownersOwner returns the CODEOWNERS owner of a creator, adding the @ to GitHub user and team names
+func codeownersOwner(creator string) (string, bool) {
+	if strings.Contains(creator, "@") && !strings.HasPrefix(creator, "@") {
+		return creator, !strings.ContainsAny(creator, " \t")
+	}
+
+	owner := strings.TrimPrefix(creator, "@")
+	if !githubOwnerPattern.MatchString(owner) {
+		return "", false
+	}
+
+	return "@" + owner, true
+}
+
+// generateReadme handles the creation of the main readme and individual action readmes
+func generateReadme(ctx context.Context, g *examples.Generator) error {
+	summary, err := g.GenerateReadme(ctx)
+	if err != nil || g.Check {
+		return err
+	}
+
+	return printReadmeSummary(g, summary)
+}
+
+// generateActionReadmes renders the README of every action from the action README template
+func generateActionReadmes(ctx context.Context, g *examples.Generator) error {
+	return g.GenerateActionReadmes(ctx)
+}
+
+// printReadmeSummary prints the README summary as a line of text, or as JSON with --json
+func printReadmeSummary(g *examples.Generator, summary examples.ReadmeSummary) error {
+	if *jsonPtr {
+		summaryBytes, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal summary: %w", err)
+		}
+		fmt.Println(string(summaryBytes))
+		return nil
+	}
+
+	fmt.Printf("Generated %s for %d actions, %d workflows (%d starters)\n", g.ReadmePath, summary.Actions, summary.Workflows, summary.Starters)
+	return nil
+}
+
+// printJSONLD prints a JSON-LD array with a schema.org SoftwareSourceCode object for each workflow,
+// in the same order as the README. LINK_BASE_URL is required, as the url of each object must be absolute
+func printJSONLD(ctx context.Context, g *examples.Generator) error {
+	if g.LinkBaseURL == "" {
+		return fmt.Errorf("json-ld requires LINK_BASE_URL, the absolute URL the workflow links are relative to, e.g. https://github.com/google-github-actions/example-workflows/blob/main/")
+	}
+	if baseURL, err := url.Parse(g.LinkBaseURL); err != nil || !baseURL.IsAbs() {
+		return fmt.Errorf("json-ld requires an absolute LINK_BASE_URL, got %q", g.LinkBaseURL)
+	}
+
+	readmeCfg, err := g.LoadReadmeConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load readme config: %w", err)
+	}
+
+	sortedActions, err := g.LoadReadmeActions(readmeCfg, true)
+	if err != nil {
+		return err
+	}
+
+	jsonLD := make([]jsonLDWorkflow, 0)
+	for _, action := range sortedActions {
+		for _, workflow := range action.Workflows {
+			jsonLD = append(jsonLD, jsonLDWorkflow{
+				Context:             "https://schema.org",
+				Type:                "SoftwareSourceCode",
+				Name:                workflow.Name,
+				Description:         workflow.Description,
+				URL:                 workflow.WorkflowURL,
+				ProgrammingLanguage: "YAML",
+				Keywords:            workflow.Categories,
+			})
+		}
+	}
+
+	jsonLDBytes, err := json.MarshalIndent(jsonLD, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON-LD: %w", err)
+	}
+	fmt.Println(string(jsonLDBytes))
+
+	return nil
+}
+
+// validateWorkflows checks the integrity of the workflow config, it only writes files with --fix, which
+// renames the properties files, updates the config and sorts the categories before validating unless
+// --dry-run is set
+func validateWorkflows(ctx context.Context, g *examples.Generator) error {
+	if *fixPtr {
+		if err := fixPropertiesFileNames(g); err != nil {
+			return err
+		}
+
+		if err := fixCategoryOrder(g); err != nil {
+			return err
+		}
+	}
+
+	_, collector, err := g.Validate()
+	if err != nil {
+		return err
+	}
+
+	if *jsonPtr {
+		problemsBytes, err := json.MarshalIndent(jsonProblems(collector.Problems), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal problems: %w", err)
+		}
+		fmt.Println(string(problemsBytes))
+
+		if failures := collector.Failures(); failures > 0 {
+			return fmt.Errorf("found %d problem(s) in %s", failures, g.ConfigPath)
+		}
+		return nil
+	}
+
+	errs := make([]examples.Problem, 0, len(collector.Problems))
+	for _, problem := range collector.Problems {
+		if problem.Warning {
+			g.Logger.Warnf("%s", problem)
+		} else {
+			errs = append(errs, problem)
+		}
+	}
+
+	for i, problem := range errs {
+		fmt.Printf("%d. %s\n", i+1, problem)
+	}
+
+	if failures := collector.Failures(); failures > 0 {
+		return fmt.Errorf("found %d problem(s) in %s", failures, g.ConfigPath)
+	}
+
+	return nil
+}
+
+// validatePropertiesFiles validates each given properties file on its own, or every properties file in the
+// config when none are given, without validating the workflows or the rest of the config
+func validatePropertiesFiles(ctx context.Context, args []string, g *examples.Generator) error {
+	propertiesPaths := args[1:]
+	if len(propertiesPaths) == 0 {
+		var wc examples.WorkflowConfig
+		if err := g.LoadWorkflowConfig(&wc); err != nil {
+			return fmt.Errorf("failed to load workflow config %s: %w", g.ConfigPath, err)
+		}
+		for _, workflowID := range examples.SortedWorkflowIDs(wc) {
+			propertiesPaths = append(propertiesPaths, wc[workflowID].PropertiesPath)
+		}
+	}
+
+	categories, err := g.LoadValidCategories()
+	if err != nil {
+		return fmt.Errorf("failed to load valid categories: %w", err)
+	}
+
+	problems := 0
+	for _, propertiesPath := range propertiesPaths {
+		g.Logger.Debugf("validating properties file %s", propertiesPath)
+
+		var errs []error
+		var properties examples.PropertiesConfig
+		if err := examples.LoadConfigFile(&properties, propertiesPath); err != nil {
+			errs = []error{fmt.Errorf("failed to load properties file: %w", err)}
+		} else {
+			errs = g.ValidateProperties(properties, categories)
+		}
+
+		for _, err := range errs {
+			problems++
+			fmt.Printf("%d. %s: %s\n", problems, propertiesPath, err)
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("found %d problem(s) in %d properties file(s)", problems, len(propertiesPaths))
+	}
+
+	return nil
+}
+
+// jsonProblems converts problems to their JSON objects, with the file and line of located problems
+// and the file of problems caused by a missing or unreadable file
+func jsonProblems(problems []examples.Problem) []jsonProblem {
+	converted := make([]jsonProblem, 0, len(problems))
+	for _, problem := range problems {
+		jp := jsonProblem{
+			WorkflowID: problem.WorkflowID,
+			Severity:   "error",
+			Message:    problem.Err.Error(),
+		}
+		if problem.Warning {
+			jp.Severity = "warning"
+		}
+
+		var located *examples.LocatedError
+		var pathErr *fs.PathError
+		if errors.As(problem.Err, &located) {
+			jp.Path, jp.Line = located.Path, located.Line
+		} else if errors.As(problem.Err, &pathErr) {
+			jp.Path = pathErr.Path
+		}
+
+		converted = append(converted, jp)
+	}
+
+	return converted
+}
+
+// checkAll runs every validation and lint, then checks that the config is formatted and the README is
+// up to date, printing one report of all problems found
+func checkAll(ctx context.Context, g *examples.Generator) error {
+	wfConfig, collector, err := g.Validate()
+	if err != nil {
+		return err
+	}
+
+	// the README can only be rendered from a valid config, so staleness is not checked when validation failed
+	if collector.Failures() == 0 {
+		readmeTemplateConfigs, err := g.LoadReadmeTemplateConfig(false)
+		if err != nil {
+			collector.Error("", err)
+		} else if err := g.CheckReadme(readmeTemplateConfigs); err != nil {
+			collector.Error("", err)
+		}
+	}
+
+	current, err := os.ReadFile(g.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read workflow config: %w", err)
+	}
+
+	formatted, err := g.MarshalWorkflowConfig(wfConfig)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(current, formatted) {
+		collector.Error("", fmt.Errorf("%s is not formatted, run 'go run scripts/generate.go fmt-config' to format it", g.ConfigPath))
+	}
+
+	errCount := 0
+	for _, problem := range collector.Problems {
+		if problem.Warning {
+			g.Logger.Warnf("%s", problem)
+		} else {
+			errCount++
+			fmt.Printf("%d. %s\n", errCount, problem)
+		}
+	}
+
+	if failures := collector.Failures(); failures > 0 {
+		return fmt.Errorf("check-all failed: found %d problem(s) in %s", failures, g.ConfigPath)
+	}
+
+	fmt.Printf("check-all passed for %s\n", g.ConfigPath)
+
+	return nil
+}
+
+// diagnoseWorkflows runs every validation and prints the problems grouped by workflow with a suggested fix for each
+func diagnoseWorkflows(ctx context.Context, g *examples.Generator) error {
+	wfConfig, collector, err := g.Validate()
+	if err != nil {
+		return err
+	}
+
+	if len(collector.Problems) == 0 {
+		fmt.Printf("no problems found in %s\n", g.ConfigPath)
+		return nil
+	}
+
+	groups := make([]string, 0)
+	groupProblems := map[string][]examples.Problem{}
+	for _, problem := range collector.Problems {
+		if _, ok := groupProblems[problem.WorkflowID]; !ok {
+			groups = append(groups, problem.WorkflowID)
+		}
+		groupProblems[problem.WorkflowID] = append(groupProblems[problem.WorkflowID], problem)
+	}
+
+	for _, group := range groups {
+		if group == "" {
+			fmt.Println("config:")
+		} else {
+			fmt.Printf("workflow %s:\n", group)
+		}
+
+		for _, problem := range groupProblems[group] {
+			if problem.Warning {
+				fmt.Printf("  warning: %s\n", problem.Err)
+			} else {
+				fmt.Printf("  problem: %s\n", problem.Err)
+			}
+			fmt.Printf("  fix:     %s\n", suggestFix(g, problem, wfConfig[problem.WorkflowID]))
+		}
+	}
+
+	failures := collector.Failures()
+	if failures == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("found %d problem(s) in %s", failures, g.ConfigPath)
+}
+
+// suggestFix explains how to fix a problem found by collectProblems
+func suggestFix(g *examples.Generator, problem examples.Problem, w examples.Workflow) string {
+	var pathErr *fs.PathError
+	switch {
+	case errors.Is(problem.Err, examples.ErrInvalidWorkflowPath):
+		return fmt.Sprintf("workflow path must be %s/<action>/<name>.yml; you provided %s", g.ConfigRelativePath(g.WorkflowsDir), w.WorkflowPath)
+	case errors.Is(problem.Err, examples.ErrOrphanedFile):
+		return fmt.Sprintf("add a workflow referencing the file to %s, or delete the file", g.ConfigPath)
+	case errors.Is(problem.Err, examples.ErrPropertiesFileName):
+		return fmt.Sprintf("rename %s after the workflow ID and update %s, or run 'go run scripts/generate.go validate --fix'", w.PropertiesPath, g.ConfigPath)
+	case errors.Is(problem.Err, examples.ErrUnsortedCategories):
+		return fmt.Sprintf("sort the categories of %s, or run 'go run scripts/generate.go validate --fix'", w.PropertiesPath)
+	case errors.Is(problem.Err, examples.ErrNoLicenseHeader):
+		return fmt.Sprintf("add the contents of %s to the top of %s", g.LicenseHeaderPath, w.WorkflowPath)
+	case errors.Is(problem.Err, examples.ErrNoStarterWorkflow):
+		return fmt.Sprintf("set starter to true for one of the action's workflows in %s", g.ConfigPath)
+	case errors.As(problem.Err, &pathErr) && errors.Is(pathErr, fs.ErrNotExist):
+		switch pathErr.Path {
+		case w.WorkflowPath:
+			return fmt.Sprintf("create %s with the workflow content, or fix the workflowPath of %s in %s", w.WorkflowPath, problem.WorkflowID, g.ConfigPath)
+		case w.PropertiesPath:
+			return fmt.Sprintf("create %s with a name and description, or fix the propertiesPath of %s in %s", w.PropertiesPath, problem.WorkflowID, g.ConfigPath)
+		default:
+			return fmt.Sprintf("create %s describing the action and its workflows", pathErr.Path)
+		}
+	case errors.As(problem.Err, new(*json.SyntaxError)):
+		return fmt.Sprintf("fix the JSON syntax of %s, look for trailing commas and missing quotes", w.PropertiesPath)
+	default:
+		return "see CONTRIBUTING.md for the expected workflow, properties and config format"
+	}
+}
+
+// fixPropertiesFileNames renames the properties files not named after their workflow ID and updates the
+// config, leaving missing files and renames that would overwrite another file to be reported by validation.
+// With --dry-run it only prints the renames, with --diff it prints the changes to the config
+func fixPropertiesFileNames(g *examples.Generator) error {
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
+		return fmt.Errorf("failed to load workflow config: %w", err)
+	}
+
+	renamed := 0
+	var renameErr error
+	for _, workflowID := range examples.SortedWorkflowIDs(wc) {
+		workflow := wc[workflowID]
+		if examples.ValidatePropertiesFileName(workflowID, workflow) == nil {
+			continue
+		}
+
+		newPropertiesPath := path.Join(path.Dir(workflow.PropertiesPath), workflowID+examples.PropertiesFileSuffix(workflow.PropertiesPath))
+		if _, err := os.Stat(workflow.PropertiesPath); err != nil {
+			continue
+		}
+		if _, err := os.Stat(newPropertiesPath); err == nil {
+			g.Logger.Warnf("not renaming %s, %s already exists", workflow.PropertiesPath, newPropertiesPath)
+			continue
+		}
+
+		if *dryRunPtr {
+			fmt.Printf("would rename: %s -> %s\n", workflow.PropertiesPath, newPropertiesPath)
+		} else {
+			// the config is still updated for the files renamed so far
+			if err := os.Rename(workflow.PropertiesPath, newPropertiesPath); err != nil {
+				renameErr = fmt.Errorf("failed to rename properties file: %w", err)
+				break
+			}
+			g.Logger.Infof("renamed %s -> %s", workflow.PropertiesPath, newPropertiesPath)
+		}
+
+		workflow.PropertiesPath = newPropertiesPath
+		wc[workflowID] = workflow
+		renamed++
+	}
+
+	if renamed == 0 {
+		return renameErr
+	}
+
+	if *diffPtr {
+		if err := printConfigDiff(g, wc); err != nil {
+			return err
+		}
+	}
+
+	if *dryRunPtr {
+		fmt.Printf("would update: %s\n", g.ConfigPath)
+		return nil
+	}
+
+	if err := g.WriteWorkflowConfig(wc); err != nil {
+		return err
+	}
+
+	return renameErr
+}
+
+// fixCategoryOrder sorts the categories of every properties file in place, leaving the rest of the file as is.
+// Files that cannot be loaded are left to be reported by validation. With --dry-run it only prints the files
+// it would update, with --diff it prints the changes to each file
+func fixCategoryOrder(g *examples.Generator) error {
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
+		return fmt.Errorf("failed to load workflow config: %w", err)
+	}
+
+	for _, workflowID := range examples.SortedWorkflowIDs(wc) {
+		propertiesPath := wc[workflowID].PropertiesPath
+
+		var properties examples.PropertiesConfig
+		if err := examples.LoadConfigFile(&properties, propertiesPath); err != nil {
+			continue
+		}
+		if examples.LintCategoryOrder(properties.Categories) == nil {
+			continue
+		}
+
+		contents, err := os.ReadFile(propertiesPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", propertiesPath, err)
+		}
+
+		updated, ok := replaceCategories(contents, examples.PropertiesFileSuffix(propertiesPath), examples.SortCategories(properties.Categories))
+		if !ok {
+			g.Logger.Warnf("not sorting the categories of %s, expected a categories array in the format of the properties template", propertiesPath)
+			continue
+		}
+
+		if *diffPtr {
+			printFileDiff(propertiesPath, contents, updated)
+		}
+
+		if *dryRunPtr {
+			fmt.Printf("would update: %s\n", propertiesPath)
+			continue
+		}
+
+		if err := examples.WriteFileAtomic(propertiesPath, updated); err != nil {
+			return err
+		}
+		g.Logger.Infof("sorted categories of %s", propertiesPath)
+	}
+
+	return nil
+}
+
+// replaceCategories replaces the categories array of a JSON or YAML properties file, formatted as the
+// properties templates render it, reporting whether the array was found
+func replaceCategories(contents []byte, suffix string, categories []string) ([]byte, bool) {
+	quoted := make([]string, 0, len(categories))
+	for _, category := range categories {
+		quoted = append(quoted, strconv.Quote(category))
+	}
+
+	if suffix == ".properties.yaml" {
+		if !yamlCategoriesPattern.Match(contents) {
+			return contents, false
+		}
+
+		items := ""
+		for _, category := range quoted {
+			items += fmt.Sprintf("  - %s\n", category)
+		}
+		return yamlCategoriesPattern.ReplaceAllLiteral(contents, []byte("categories:\n"+items)), true
+	}
+
+	match := jsonCategoriesPattern.FindSubmatchIndex(contents)
+	if match == nil {
+		return contents, false
+	}
+
+	updated := append([]byte{}, contents[:match[3]]...)
+	updated = append(updated, fmt.Sprintf("[%s]", strings.Join(quoted, ", "))...)
+	return append(updated, contents[match[1]:]...), true
+}
+
+// renderProperties renders the properties template for a new workflow
+func renderProperties(workflowID string, propertiesFilePath string) error {
+	categories := examples.TypeCategories[examples.BaseType(*typePtr)]
+	if *categoriesPtr != "" {
+		categories = make([]string, 0)
+		for _, category := range strings.Split(*categoriesPtr, ",") {
+			categories = append(categories, strings.TrimSpace(category))
+		}
+	}
+
+	templateConfig := &examples.PropertiesTemplateConfig{
+		WorkflowID: workflowID,
+		Title:      examples.Humanize(workflowID),
+		Type:       *typePtr,
+		Creator:    *creatorPtr,
+		IconName:   "google-cloud",
+		Categories: examples.SortCategories(categories),
+	}
+
+	if err := examples.RenderDataTemplateFile(propertiesTemplPath, propertiesFilePath, templateConfig); err != nil {
+		return fmt.Errorf("failed to render properties template: %w", err)
+	}
+
+	return nil
+}
+
+// writeClonedProperties renders the properties template for a new workflow with the creator,
+// icon and categories of existing properties, the name and description are placeholders
+func writeClonedProperties(properties examples.PropertiesConfig, workflowID string, propertiesFilePath string) error {
+	templateConfig := &examples.PropertiesTemplateConfig{
+		WorkflowID: workflowID,
+		Title:      examples.Humanize(workflowID),
+		Type:       *typePtr,
+		Creator:    properties.Creator,
+		IconName:   properties.IconName,
+		Categories: examples.SortCategories(properties.Categories),
+	}
+
+	if err := examples.RenderDataTemplateFile(propertiesTemplPath, propertiesFilePath, templateConfig); err != nil {
+		return fmt.Errorf("failed to render properties template: %w", err)
+	}
+
+	return nil
+}
+
+// printConfigDiff prints the lines of the workflow config on disk that differ from wc
+func printConfigDiff(g *examples.Generator, wc examples.WorkflowConfig) error {
+	current, err := os.ReadFile(g.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read workflow config: %w", err)
+	}
+
+	updated, err := g.MarshalWorkflowConfig(wc)
+	if err != nil {
+		return err
+	}
+
+	printFileDiff(g.ConfigPath, current, updated)
+
+	return nil
+}
+
+// printFileDiff prints the lines that differ between the current and updated contents of a file
+func printFileDiff(filePath string, current []byte, updated []byte) {
+	fmt.Printf("--- %s\n+++ %s\n", filePath, filePath)
+	for _, line := range examples.LineDiff(strings.Split(string(current), "\n"), strings.Split(string(updated), "\n")) {
+		fmt.Println(line)
+	}
+}
+
+// formatWorkflowConfig rewrites the workflow config in its canonical form,
+// with --check it only reports whether the config is already formatted
+func formatWorkflowConfig(ctx context.Context, g *examples.Generator) error {
+	var wc examples.WorkflowConfig
+	if err := g.LoadWorkflowConfig(&wc); err != nil {
+		return fmt.Errorf("failed to load workflow config: %w", err)
+	}
+
+	current, err := os.ReadFile(g.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read workflow config: %w", err)
+	}
+
+	formatted, err := g.MarshalWorkflowConfig(wc)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(current, formatted) {
+		return nil
+	}
+
+	if *checkPtr {
+		return fmt.Errorf("%s is not formatted, run 'go run scripts/generate.go fmt-config' to format it", g.ConfigPath)
+	}
+
+	if err := examples.MkdirParent(g.ConfigPath); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(g.ConfigPath, formatted, 0644); err != nil {
+		return fmt.Errorf("failed to write formatted workflow config: %w", err)
+	}
+	g.Logger.Infof("formatted %s", g.ConfigPath)
+
+	return nil
+}
+
+// isFlagSet reports whether a flag was explicitly passed on the command line
+func isFlagSet(name string) bool {
+	isSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			isSet = true
+		}
+	})
+	return isSet
+}
+
+// defaultEnv sets a default value for a missing environment variable
+func defaultEnv(key string, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return defaultValue
+}
 
-// readmeAction is the action template config used for the index README template
-type readmeAction struct {
-	Name       string
-	Path       string
-	ReadMePath string
-	Workflows  []readmeWorkflow
+// jsonProblem is the object printed for each problem by validate --json, e.g. for editor diagnostics
+type jsonProblem struct {
+	WorkflowID string `json:"workflowID,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
 }
 
-// readmeWorkflow is the workflow config used for the index README template
-type readmeWorkflow struct {
-	Name           string
-	RelativeName   string
-	Description    string
-	Starter        bool
-	WorkflowPath   string
-	PropertiesPath string
+// workflowStats is the number of workflows per type, per category and by starter status
+type workflowStats struct {
+	Types       map[string]int `json:"types"`
+	Categories  map[string]int `json:"categories"`
+	Starters    int            `json:"starters"`
+	NonStarters int            `json:"nonStarters"`
 }
 
-// readmeTemplateConfig is the template config used for the index README template
-type readmeTemplateConfig struct {
-	Title   string
-	Actions []readmeAction
+// jsonLDWorkflow is the schema.org SoftwareSourceCode object printed for each workflow by the json-ld command
+type jsonLDWorkflow struct {
+	Context             string   `json:"@context"`
+	Type                string   `json:"@type"`
+	Name                string   `json:"name"`
+	Description         string   `json:"description"`
+	URL                 string   `json:"url"`
+	ProgrammingLanguage string   `json:"programmingLanguage"`
+	Keywords            []string `json:"keywords,omitempty"`
 }
@@ -0,0 +1,3762 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+
+// Run with: go test scripts/generate.go scripts/generate_test.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google-github-actions/example-workflows/pkg/examples"
+)
+
+// testTemplateDir is the templates directory of the repository, resolved before the tests change directory
+var testTemplateDir, _ = filepath.Abs(filepath.Join("..", "templates"))
+
+// testWorkflowContents is a workflow that passes every validation
+const testWorkflowContents = `name: Deploy App
+
+on:
+  push:
+    branches:
+      - main
+
+permissions:
+  contents: read
+
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`
+
+// testConfig is a workflow config with the workflow of testRepoFiles
+const testConfig = `{
+  "deploy-app": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml",
+    "propertiesPath": "properties/deploy-app.properties.json"
+  }
+}
+`
+
+// testRepoFiles returns the files of a repository with a single valid starter workflow
+func testRepoFiles() map[string]string {
+	return map[string]string{
+		"workflow.config.json":                     testConfig,
+		"workflows/deploy-cloudrun/README.md":      "# deploy-cloudrun\n",
+		"workflows/deploy-cloudrun/deploy-app.yml": testWorkflowContents,
+		"properties/deploy-app.properties.json":    testProperties(`["Cloud Run", "Deployment"]`),
+		"README.md":                                "",
+	}
+}
+
+// newTestRepo writes the files of testRepoFiles, updated with files, to a temporary directory
+// and returns its path. A file with empty contents in files is not written.
+func newTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	repoFiles := testRepoFiles()
+	for name, contents := range files {
+		repoFiles[name] = contents
+	}
+
+	dir := t.TempDir()
+	for name, contents := range repoFiles {
+		if contents == "" && name != "README.md" {
+			continue
+		}
+		writeTestFile(t, filepath.Join(dir, name), contents)
+	}
+
+	return dir
+}
+
+// writeTestFile writes a file, creating its parent directories
+func writeTestFile(t *testing.T, filePath string, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readTestFile returns the contents of a file, failing the test when it cannot be read
+func readTestFile(t *testing.T, filePath string) string {
+	t.Helper()
+
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(contents)
+}
+
+// chdir changes the working directory to dir for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// resetFlags sets every flag of the script back to its default and forgets which flags were set,
+// so each test parses its flags as a fresh run of the script would
+func resetFlags() {
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		// the flags of go test keep their values
+		if !strings.HasPrefix(f.Name, "test.") {
+			_ = f.Value.Set(f.DefValue)
+		}
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	flag.CommandLine = fs
+}
+
+// runGenerate runs the script with args in dir, with the templates of the repository unless
+// --template-dir is passed, and returns what it printed to stdout and logged
+func runGenerate(t *testing.T, dir string, args ...string) (string, string, error) {
+	t.Helper()
+
+	chdir(t, dir)
+	resetFlags()
+	if err := flag.CommandLine.Parse(append([]string{"--template-dir=" + testTemplateDir}, args...)); err != nil {
+		t.Fatal(err)
+	}
+	commandArgs := parseCommandFlags(flag.Args())
+
+	level := examples.LevelInfo
+	if *verbosePtr {
+		level = examples.LevelDebug
+	}
+
+	var logs bytes.Buffer
+	var err error
+	stdout := captureStdout(t, func() {
+		err = realMain(context.Background(), examples.NewLogger(&logs, level), commandArgs)
+	})
+
+	return stdout, logs.String(), err
+}
+
+// captureStdout returns what fn printed to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	stdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+
+	return readTestFile(t, file.Name())
+}
+
+// logLines returns the non-empty lines of logs
+func logLines(logs string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(logs, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name       string
+		files      map[string]string
+		wantErr    string
+		wantErrors []string
+	}{
+		{
+			name:       "valid",
+			wantErrors: []string{},
+		},
+		{
+			name: "missing_properties_file",
+			files: map[string]string{
+				"properties/deploy-app.properties.json": "",
+			},
+			wantErr: "found 1 problem(s) in workflow.config.json",
+			wantErrors: []string{
+				"1. workflow deploy-app: failed to validate properties/deploy-app.properties.json exists: stat properties/deploy-app.properties.json: no such file or directory",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+
+			stdout, logs, err := runGenerate(t, dir, "validate")
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("validate error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+			if logs != "" {
+				t.Errorf("validate logged %q, want no warnings", logs)
+			}
+		})
+	}
+}
+
+// snapshotFiles returns the contents of every file under dir by slash-separated relative path
+func snapshotFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(filePath string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(relPath)] = readTestFile(t, filePath)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return files
+}
+
+func TestWorkflowDryRun(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		wantOuts []string
+	}{
+		{
+			name:     "new_action",
+			args:     []string{"workflow", "--dry-run", "auth/auth-simple"},
+			wantOuts: []string{"workflows/auth/auth-simple.yml", "properties/auth-simple.properties.json", "workflows/auth/README.md"},
+		},
+		{
+			name:     "existing_action",
+			args:     []string{"workflow", "--dry-run", "--starter", "deploy-cloudrun/deploy-source"},
+			wantOuts: []string{"workflows/deploy-cloudrun/deploy-source.yml", "properties/deploy-source.properties.json"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			before := snapshotFiles(t, dir)
+
+			stdout, _, err := runGenerate(t, dir, tc.args...)
+			if err != nil {
+				t.Fatalf("workflow --dry-run: %s", err)
+			}
+
+			if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+				t.Errorf("workflow --dry-run changed the files of %s", dir)
+			}
+			for _, out := range tc.wantOuts {
+				if !strings.Contains(stdout, out) {
+					t.Errorf("workflow --dry-run printed %q, want it to list %s", stdout, out)
+				}
+			}
+		})
+	}
+}
+
+func TestReadmeInvalidWorkflowYAML(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{
+		"workflow.config.json": `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-jobless": {"starter": false, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-jobless.yml", "propertiesPath": "properties/deploy-jobless.properties.json"}
+}
+`,
+		"workflows/deploy-cloudrun/deploy-app.yml":     testWorkflowContents + "        with: {ref: main\n",
+		"workflows/deploy-cloudrun/deploy-jobless.yml": strings.Split(testWorkflowContents, "jobs:")[0],
+		"properties/deploy-jobless.properties.json":    `{"name": "Deploy Jobless", "description": "Deploy without jobs.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run", "Deployment"]}` + "\n",
+	})
+
+	_, logs, err := runGenerate(t, dir, "readme")
+	if err == nil || err.Error() != "failed to process invalid configs" {
+		t.Fatalf("readme error = %v, want failed to process invalid configs", err)
+	}
+
+	// both workflows are reported instead of stopping at the first
+	want := []string{
+		"error: validation failed for generate readme workflow deploy-app: invalid YAML in workflows/deploy-cloudrun/deploy-app.yml: yaml: line 15: did not find expected ',' or '}'",
+		`error: validation failed for generate readme workflow deploy-jobless: invalid workflow workflows/deploy-cloudrun/deploy-jobless.yml: missing top-level "jobs" key`,
+	}
+	if got := logLines(logs); !reflect.DeepEqual(got, want) {
+		t.Errorf("readme logged %q, want %q", got, want)
+	}
+	if got := readTestFile(t, filepath.Join(dir, "README.md")); got != "" {
+		t.Errorf("readme wrote README.md with invalid workflows:\n%s", got)
+	}
+}
+
+func TestReadmeManifest(t *testing.T) {
+	dir := newTestRepo(t, nil)
+	manifestPath := filepath.Join(dir, "manifest.json")
+	t.Setenv("MANIFEST_PATH", manifestPath)
+
+	if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+		t.Fatalf("readme: %s", err)
+	}
+
+	var got []examples.ManifestWorkflow
+	if err := json.Unmarshal([]byte(readTestFile(t, manifestPath)), &got); err != nil {
+		t.Fatalf("failed to unmarshal manifest: %s", err)
+	}
+
+	want := []examples.ManifestWorkflow{
+		{
+			ActionName:   "deploy-cloudrun",
+			WorkflowName: "Deploy App",
+			Description:  "Deploy an app to Cloud Run.",
+			RelativeName: "deploy-app",
+			Starter:      true,
+			Categories:   []string{"Cloud Run", "Deployment"},
+			WorkflowPath: "workflows/deploy-cloudrun/deploy-app.yml",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("manifest = %#v, want %#v", got, want)
+	}
+}
+
+// testSecondWorkflowFiles adds a second workflow, deploy-other, to the deploy-cloudrun action of testRepoFiles
+var testSecondWorkflowFiles = map[string]string{
+	"workflow.config.json": `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-other": {"starter": false, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-other.yml", "propertiesPath": "properties/deploy-other.properties.json"}
+}
+`,
+	"workflows/deploy-cloudrun/deploy-other.yml": strings.Replace(testWorkflowContents, "Deploy App", "Deploy Other", 1),
+	"properties/deploy-other.properties.json":    `{"name": "Deploy Other", "description": "Deploy another app to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run", "Deployment"]}` + "\n",
+}
+
+func TestDeleteWorkflow(t *testing.T) {
+	cases := []struct {
+		name       string
+		files      map[string]string
+		args       []string
+		wantErr    string
+		wantConfig string
+		wantLogs   []string
+	}{
+		{
+			name:       "remaining_workflows",
+			files:      testSecondWorkflowFiles,
+			args:       []string{"delete", "deploy-cloudrun/deploy-other"},
+			wantConfig: testConfig,
+			wantLogs:   []string{},
+		},
+		{
+			name:       "last_workflow",
+			args:       []string{"delete", "deploy-cloudrun/deploy-app"},
+			wantConfig: "{}\n",
+			wantLogs: []string{
+				"warning: workflows/deploy-cloudrun has no remaining workflows, consider removing workflows/deploy-cloudrun/README.md",
+			},
+		},
+		{
+			name:    "unknown_workflow",
+			args:    []string{"delete", "deploy-cloudrun/deploy-missing"},
+			wantErr: "workflow deploy-missing does not exist in workflow.config.json",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+			before := snapshotFiles(t, dir)
+
+			_, logs, err := runGenerate(t, dir, tc.args...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("delete error = %v, want %s", err, tc.wantErr)
+				}
+				if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+					t.Errorf("delete changed the files of %s after failing", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("delete: %s", err)
+			}
+
+			workflowID := path.Base(tc.args[1])
+			for _, orphan := range []string{
+				path.Join("workflows", tc.args[1]+".yml"),
+				path.Join("properties", workflowID+".properties.json"),
+			} {
+				if _, err := os.Stat(filepath.Join(dir, orphan)); !os.IsNotExist(err) {
+					t.Errorf("delete left %s behind", orphan)
+				}
+			}
+			if got := readTestFile(t, filepath.Join(dir, "workflow.config.json")); got != tc.wantConfig {
+				t.Errorf("delete wrote config:\n%s\nwant:\n%s", got, tc.wantConfig)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "workflows/deploy-cloudrun/README.md")); err != nil {
+				t.Errorf("delete removed the action README: %s", err)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("delete logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+// testProperties returns the properties file of deploy-app with categories
+func testProperties(categories string) string {
+	return `{"name": "Deploy App", "description": "Deploy an app to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ` + categories + "}\n"
+}
+
+func TestValidateCategories(t *testing.T) {
+	cases := []struct {
+		name       string
+		files      map[string]string
+		wantErrors []string
+	}{
+		{
+			name:       "valid",
+			files:      map[string]string{"properties/deploy-app.properties.json": testProperties(`["Cloud Run", "Deployment"]`)},
+			wantErrors: []string{},
+		},
+		{
+			name:  "invalid",
+			files: map[string]string{"properties/deploy-app.properties.json": testProperties(`["Cloud Run", "Deployements"]`)},
+			wantErrors: []string{
+				`1. workflow deploy-app: invalid category "Deployements", see categories.json or the default categories for allowed values`,
+			},
+		},
+		{
+			name:  "empty",
+			files: map[string]string{"properties/deploy-app.properties.json": testProperties(`[]`)},
+			wantErrors: []string{
+				"1. workflow deploy-app: properties categories must not be empty",
+			},
+		},
+		{
+			name: "categories_file",
+			files: map[string]string{
+				"categories.json":                       `["Cloud Run", "Deployements"]`,
+				"properties/deploy-app.properties.json": testProperties(`["Cloud Run", "Deployements"]`),
+			},
+			wantErrors: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+
+			stdout, _, err := runGenerate(t, dir, "validate")
+			if len(tc.wantErrors) == 0 && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if len(tc.wantErrors) > 0 && err == nil {
+				t.Fatalf("validate succeeded, want %q", tc.wantErrors)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+		})
+	}
+}
+
+// testNestedWorkflowFiles adds a workflow with a workflowPath under workflows/deploy-cloudrun to testRepoFiles
+func testNestedWorkflowFiles(workflowPath string) map[string]string {
+	workflowID := strings.TrimSuffix(path.Base(workflowPath), ".yml")
+	name := examples.TitleCase(workflowID)
+
+	return map[string]string{
+		"workflow.config.json": `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "` + workflowID + `": {"starter": false, "type": "deployments", "workflowPath": "` + workflowPath + `", "propertiesPath": "properties/` + workflowID + `.properties.json"}
+}
+`,
+		workflowPath: strings.Replace(testWorkflowContents, "Deploy App", name, 1),
+		"properties/" + workflowID + ".properties.json": strings.Replace(testProperties(`["Cloud Run", "Deployment"]`), "Deploy App", name, 1),
+	}
+}
+
+func TestReadmeNestedGroups(t *testing.T) {
+	cases := []struct {
+		name         string
+		workflowPath string
+		wantGroup    string
+	}{
+		{
+			name:         "three_levels",
+			workflowPath: "workflows/deploy-cloudrun/docker/prod.yml",
+			wantGroup: `#### docker
+
+| Name                                                         | Starter                   | Description      |
+| ------------------------------------------------------------ | ------------------------- | ---------------- |
+|[prod](workflows/deploy-cloudrun/docker/prod.yml) |  | Deploy an app to Cloud Run. |
+`,
+		},
+		{
+			name:         "four_levels",
+			workflowPath: "workflows/deploy-cloudrun/docker/eu/canary.yml",
+			wantGroup: `#### docker/eu
+
+| Name                                                         | Starter                   | Description      |
+| ------------------------------------------------------------ | ------------------------- | ---------------- |
+|[canary](workflows/deploy-cloudrun/docker/eu/canary.yml) |  | Deploy an app to Cloud Run. |
+`,
+		},
+	}
+
+	// the workflows directly in the action folder stay ungrouped
+	ungrouped := `| ------------------------------------------------------------ | ------------------------- | ---------------- |
+|[deploy-app](workflows/deploy-cloudrun/deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |
+
+#### `
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, testNestedWorkflowFiles(tc.workflowPath))
+
+			if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			readme := readTestFile(t, filepath.Join(dir, "README.md"))
+			for _, want := range []string{ungrouped, tc.wantGroup} {
+				if !strings.Contains(readme, want) {
+					t.Errorf("readme wrote:\n%s\nwant it to contain:\n%s", readme, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenameWorkflow(t *testing.T) {
+	cases := []struct {
+		name       string
+		files      map[string]string
+		args       []string
+		wantErr    string
+		wantConfig string
+	}{
+		{
+			name: "renamed",
+			args: []string{"rename", "deploy-cloudrun/deploy-app", "deploy-cloudrun/docker/deploy-docker"},
+			wantConfig: `{
+  "deploy-docker": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-cloudrun/docker/deploy-docker.yml",
+    "propertiesPath": "properties/deploy-docker.properties.json"
+  }
+}
+`,
+		},
+		{
+			name:    "new_id_exists",
+			files:   testSecondWorkflowFiles,
+			args:    []string{"rename", "deploy-cloudrun/deploy-app", "deploy-cloudrun/deploy-other"},
+			wantErr: "workflow deploy-other already exists in workflow.config.json, please use a different name",
+		},
+		{
+			name:    "old_id_missing",
+			args:    []string{"rename", "deploy-cloudrun/deploy-missing", "deploy-cloudrun/deploy-docker"},
+			wantErr: "workflow deploy-missing does not exist in workflow.config.json",
+		},
+		{
+			name:    "properties_rename_fails",
+			files:   map[string]string{"properties/deploy-app.properties.json": ""},
+			args:    []string{"rename", "deploy-cloudrun/deploy-app", "deploy-cloudrun/deploy-docker"},
+			wantErr: "failed to move properties file: rename properties/deploy-app.properties.json properties/deploy-docker.properties.json: no such file or directory",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+			before := snapshotFiles(t, dir)
+
+			_, _, err := runGenerate(t, dir, tc.args...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("rename error = %v, want %s", err, tc.wantErr)
+				}
+				if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+					t.Errorf("rename changed the files of %s after failing", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("rename: %s", err)
+			}
+
+			for _, oldPath := range []string{"workflows/deploy-cloudrun/deploy-app.yml", "properties/deploy-app.properties.json"} {
+				if _, err := os.Stat(filepath.Join(dir, oldPath)); !os.IsNotExist(err) {
+					t.Errorf("rename left %s behind", oldPath)
+				}
+			}
+			after := snapshotFiles(t, dir)
+			if got, want := after["workflows/deploy-cloudrun/docker/deploy-docker.yml"], before["workflows/deploy-cloudrun/deploy-app.yml"]; got != want {
+				t.Errorf("rename moved the workflow with contents:\n%s\nwant:\n%s", got, want)
+			}
+			if got, want := after["properties/deploy-docker.properties.json"], before["properties/deploy-app.properties.json"]; got != want {
+				t.Errorf("rename moved the properties file with contents:\n%s\nwant:\n%s", got, want)
+			}
+			if got := after["workflow.config.json"]; got != tc.wantConfig {
+				t.Errorf("rename wrote config:\n%s\nwant:\n%s", got, tc.wantConfig)
+			}
+		})
+	}
+}
+
+func TestReadmeDuplicateNames(t *testing.T) {
+	files := map[string]string{}
+	for name, contents := range testSecondWorkflowFiles {
+		files[name] = contents
+	}
+	files["properties/deploy-other.properties.json"] = strings.Replace(files["properties/deploy-other.properties.json"], "Deploy Other", "Deploy App", 1)
+	dir := newTestRepo(t, files)
+
+	_, logs, err := runGenerate(t, dir, "readme")
+	if err == nil || err.Error() != "failed to process invalid configs" {
+		t.Fatalf("readme error = %v, want failed to process invalid configs", err)
+	}
+
+	want := []string{`error: validation failed for generate readme: properties name "Deploy App" is used by multiple workflows: deploy-app, deploy-other`}
+	if got := logLines(logs); !reflect.DeepEqual(got, want) {
+		t.Errorf("readme logged %q, want %q", got, want)
+	}
+}
+
+func TestValidateIcons(t *testing.T) {
+	cases := []struct {
+		name       string
+		iconsDir   string
+		files      map[string]string
+		wantErrors []string
+	}{
+		{
+			name:       "unset",
+			wantErrors: []string{},
+		},
+		{
+			name:       "present",
+			iconsDir:   "icons",
+			files:      map[string]string{"icons/google-cloud.svg": "<svg></svg>\n"},
+			wantErrors: []string{},
+		},
+		{
+			name:     "absent",
+			iconsDir: "icons",
+			files:    map[string]string{"icons/gcp.svg": "<svg></svg>\n"},
+			wantErrors: []string{
+				`1. workflow deploy-app: icon "google-cloud" does not exist: stat icons/google-cloud.svg: no such file or directory`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("ICONS_DIR", tc.iconsDir)
+			dir := newTestRepo(t, tc.files)
+
+			stdout, _, err := runGenerate(t, dir, "validate")
+			if len(tc.wantErrors) == 0 && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if len(tc.wantErrors) > 0 && err == nil {
+				t.Fatalf("validate succeeded, want %q", tc.wantErrors)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+		})
+	}
+}
+
+func TestListWorkflows(t *testing.T) {
+	files := map[string]string{}
+	for name, contents := range testSecondWorkflowFiles {
+		files[name] = contents
+	}
+	files["workflow.config.json"] = strings.Replace(files["workflow.config.json"], `"deploy-other": {"starter": false, "type": "deployments"`, `"deploy-other": {"starter": false, "type": "ci"`, 1)
+
+	cases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "all",
+			args: []string{"list"},
+			want: []string{"deploy-app", "deploy-other"},
+		},
+		{
+			name: "type",
+			args: []string{"list", "--type", "ci"},
+			want: []string{"deploy-other"},
+		},
+		{
+			name: "starter",
+			args: []string{"list", "--starter"},
+			want: []string{"deploy-app"},
+		},
+		{
+			name: "not_starter",
+			args: []string{"list", "--starter=false"},
+			want: []string{"deploy-other"},
+		},
+		{
+			name: "type_and_starter",
+			args: []string{"list", "--type", "ci", "--starter"},
+			want: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, files)
+			before := snapshotFiles(t, dir)
+
+			stdout, _, err := runGenerate(t, dir, tc.args...)
+			if err != nil {
+				t.Fatalf("list: %s", err)
+			}
+
+			lines := logLines(stdout)
+			if len(lines) == 0 || !strings.HasPrefix(lines[0], "ID ") {
+				t.Fatalf("list printed %q, want a table", stdout)
+			}
+			got := make([]string, 0)
+			for _, line := range lines[1:] {
+				got = append(got, strings.Fields(line)[0])
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("list printed workflows %q, want %q", got, tc.want)
+			}
+
+			// --json prints the same workflows
+			stdout, _, err = runGenerate(t, dir, append(tc.args, "--json")...)
+			if err != nil {
+				t.Fatalf("list --json: %s", err)
+			}
+			var wc examples.WorkflowConfig
+			if err := json.Unmarshal([]byte(stdout), &wc); err != nil {
+				t.Fatalf("failed to unmarshal list --json: %s", err)
+			}
+			if got := examples.SortedWorkflowIDs(wc); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("list --json printed workflows %q, want %q", got, tc.want)
+			}
+
+			if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+				t.Errorf("list changed the files of %s", dir)
+			}
+		})
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "missing_field",
+			config:  `{"deploy-app": {"starter": true, "type": "deployments", "propertiesPath": "properties/deploy-app.properties.json"}}`,
+			wantErr: "failed schema validation:\n  /deploy-app: missing properties: 'workflowPath'",
+		},
+		{
+			name:    "invalid_type",
+			config:  `{"deploy-app": {"starter": true, "type": "deployment", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"}}`,
+			wantErr: "failed schema validation:\n  /deploy-app/type: does not match pattern '^(automation|ci|code-scanning|deployments)(/[a-z0-9-]+)*$'",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			for _, args := range [][]string{{"validate"}, {"readme"}, {"workflow", "deploy-cloudrun/deploy-new"}} {
+				dir := newTestRepo(t, map[string]string{"workflow.config.json": tc.config})
+
+				_, _, err := runGenerate(t, dir, args...)
+				if err == nil || !strings.HasSuffix(err.Error(), tc.wantErr) {
+					t.Errorf("%s error = %v, want ...%s", args[0], err, tc.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkflowPropertiesDefaults(t *testing.T) {
+	cases := []struct {
+		name           string
+		args           []string
+		propertiesPath string // properties/deploy-new.properties.json when empty
+		wantCreator    string
+		wantCategories []string
+	}{
+		{
+			name:           "deployments",
+			args:           []string{"workflow", "deploy-cloudrun/deploy-new"},
+			wantCreator:    "Google Cloud",
+			wantCategories: []string{"Deployment"},
+		},
+		{
+			name:           "ci",
+			args:           []string{"workflow", "--type", "ci", "deploy-cloudrun/deploy-new"},
+			wantCreator:    "Google Cloud",
+			wantCategories: []string{"Continuous integration"},
+		},
+		{
+			name:           "overridden",
+			args:           []string{"workflow", "--creator", "Example Org", "--categories", "Cloud Run,Deployment", "deploy-cloudrun/deploy-new"},
+			wantCreator:    "Example Org",
+			wantCategories: []string{"Cloud Run", "Deployment"},
+		},
+		{
+			// the values are written as they are, neither HTML-escaped nor breaking the quoting
+			name:           "special_characters",
+			args:           []string{"workflow", "--creator", `O'Brien & "Co" \ Ltd`, "--categories", `Cloud Run,R&D "Labs"`, "deploy-cloudrun/deploy-new"},
+			wantCreator:    `O'Brien & "Co" \ Ltd`,
+			wantCategories: []string{"Cloud Run", `R&D "Labs"`},
+		},
+		{
+			name:           "special_characters_yaml",
+			args:           []string{"workflow", "--format", "yaml", "--creator", `O'Brien & "Co" \ Ltd`, "--categories", `Cloud Run,R&D "Labs"`, "deploy-cloudrun/deploy-new"},
+			propertiesPath: "properties/deploy-new.properties.yaml",
+			wantCreator:    `O'Brien & "Co" \ Ltd`,
+			wantCategories: []string{"Cloud Run", `R&D "Labs"`},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+
+			if _, _, err := runGenerate(t, dir, tc.args...); err != nil {
+				t.Fatalf("workflow: %s", err)
+			}
+
+			propertiesPath := tc.propertiesPath
+			if propertiesPath == "" {
+				propertiesPath = "properties/deploy-new.properties.json"
+			}
+
+			var properties examples.PropertiesConfig
+			if err := examples.LoadConfigFile(&properties, filepath.Join(dir, propertiesPath)); err != nil {
+				t.Fatalf("failed to load properties: %s", err)
+			}
+			if properties.Creator != tc.wantCreator {
+				t.Errorf("workflow wrote creator %q, want %q", properties.Creator, tc.wantCreator)
+			}
+			if !reflect.DeepEqual(properties.Categories, tc.wantCategories) {
+				t.Errorf("workflow wrote categories %q, want %q", properties.Categories, tc.wantCategories)
+			}
+		})
+	}
+}
+
+func TestReadmeStubWorkflow(t *testing.T) {
+	cases := []struct {
+		name     string
+		workflow string
+		wantLogs []string
+	}{
+		{
+			name:     "real",
+			workflow: testWorkflowContents,
+			wantLogs: []string{},
+		},
+		{
+			name:     "stub",
+			workflow: examples.WorkflowStubContents,
+			wantLogs: []string{
+				"error: validation failed for generate readme workflow deploy-app: workflow workflows/deploy-cloudrun/deploy-app.yml is still the generated stub, add the workflow content",
+			},
+		},
+		{
+			name:     "too_short",
+			workflow: "name: Deploy App\n",
+			wantLogs: []string{
+				"error: validation failed for generate readme workflow deploy-app: workflow workflows/deploy-cloudrun/deploy-app.yml is too short to be a complete workflow, expected at least 64 bytes",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, map[string]string{"workflows/deploy-cloudrun/deploy-app.yml": tc.workflow})
+
+			_, logs, err := runGenerate(t, dir, "readme")
+			if len(tc.wantLogs) == 0 && err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+			if len(tc.wantLogs) > 0 && (err == nil || err.Error() != "failed to process invalid configs") {
+				t.Fatalf("readme error = %v, want failed to process invalid configs", err)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("readme logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestWorkflowScaffoldDirs(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{"scratch/workflow.config.json": "{}\n"})
+	before := snapshotFiles(t, dir)
+
+	_, _, err := runGenerate(t, dir, "workflow", "--config", "scratch/workflow.config.json", "--workflows-dir", "scratch/flows", "--properties-dir", "scratch/props", "deploy-cloudrun/deploy-new")
+	if err != nil {
+		t.Fatalf("workflow: %s", err)
+	}
+
+	changed := make([]string, 0)
+	after := snapshotFiles(t, dir)
+	for name, contents := range after {
+		if before[name] != contents {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	want := []string{
+		"scratch/flows/deploy-cloudrun/README.md",
+		"scratch/flows/deploy-cloudrun/deploy-new.yml",
+		"scratch/props/deploy-new.properties.json",
+		"scratch/workflow.config.json",
+	}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("workflow wrote %q, want %q", changed, want)
+	}
+
+	// the paths in the config are relative to its directory
+	wantConfig := `{
+  "deploy-new": {
+    "starter": false,
+    "type": "deployments",
+    "workflowPath": "flows/deploy-cloudrun/deploy-new.yml",
+    "propertiesPath": "props/deploy-new.properties.json"
+  }
+}
+`
+	if got := after["scratch/workflow.config.json"]; got != wantConfig {
+		t.Errorf("workflow wrote config:\n%s\nwant:\n%s", got, wantConfig)
+	}
+}
+
+func TestReadmeMatchesLibrary(t *testing.T) {
+	cliDir := newTestRepo(t, testSecondWorkflowFiles)
+	if _, _, err := runGenerate(t, cliDir, "readme"); err != nil {
+		t.Fatalf("readme: %s", err)
+	}
+
+	libraryDir := newTestRepo(t, testSecondWorkflowFiles)
+	chdir(t, libraryDir)
+	g := examples.NewGenerator(examples.Options{TemplateDir: testTemplateDir})
+	if _, err := g.GenerateReadme(context.Background()); err != nil {
+		t.Fatalf("GenerateReadme: %s", err)
+	}
+
+	got, want := readTestFile(t, filepath.Join(cliDir, "README.md")), readTestFile(t, filepath.Join(libraryDir, "README.md"))
+	if got != want {
+		t.Errorf("readme wrote:\n%s\nwant the README of GenerateReadme:\n%s", got, want)
+	}
+	if !strings.Contains(got, "deploy-other") {
+		t.Errorf("readme wrote:\n%s\nwant it to list deploy-other", got)
+	}
+}
+
+func TestReadmeAliases(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{
+		"properties/deploy-app.properties.json": `{"name": "Deploy App", "description": "Deploy an app to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run", "Deployment"], "aliases": ["Cloud Run Deploy", "Ship App"]}` + "\n",
+	})
+
+	if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+		t.Fatalf("readme: %s", err)
+	}
+
+	readme := readTestFile(t, filepath.Join(dir, "README.md"))
+	want := "|[deploy-app](workflows/deploy-cloudrun/deploy-app.yml) | ✅ | Deploy an app to Cloud Run. Also known as: _Cloud Run Deploy_, _Ship App_. |\n"
+	if !strings.Contains(readme, want) {
+		t.Errorf("readme wrote:\n%s\nwant it to contain:\n%s", readme, want)
+	}
+
+	// aliases annotate the workflow instead of adding entries
+	if got := strings.Count(readme, "](workflows/deploy-cloudrun/deploy-app.yml)"); got != 1 {
+		t.Errorf("readme linked the workflow %d times, want 1", got)
+	}
+}
+
+func TestReadmeTableOfContents(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{
+		"workflow.config.json":                      strings.Replace(testConfig, "workflows/deploy-cloudrun/", "workflows/deploy cloud.run/", 1),
+		"workflows/deploy-cloudrun/README.md":       "",
+		"workflows/deploy-cloudrun/deploy-app.yml":  "",
+		"workflows/deploy cloud.run/README.md":      "# deploy cloud.run\n",
+		"workflows/deploy cloud.run/deploy-app.yml": testWorkflowContents,
+	})
+
+	if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+		t.Fatalf("readme: %s", err)
+	}
+
+	readme := readTestFile(t, filepath.Join(dir, "README.md"))
+	for _, want := range []string{"- [deploy cloud.run](#deploy-cloudrun)\n", "### [deploy cloud.run]("} {
+		if !strings.Contains(readme, want) {
+			t.Errorf("readme wrote:\n%s\nwant it to contain:\n%s", readme, want)
+		}
+	}
+}
+
+func TestReadmeCheck(t *testing.T) {
+	cases := []struct {
+		name     string
+		readme   func(string) string
+		wantErr  bool
+		wantDiff string
+	}{
+		{
+			name:   "matching",
+			readme: func(readme string) string { return readme },
+		},
+		{
+			name: "mismatching",
+			readme: func(readme string) string {
+				return strings.Replace(readme, "Deploy an app to Cloud Run.", "Deploy an app.", 1)
+			},
+			wantErr:  true,
+			wantDiff: "+ |[deploy-app](workflows/deploy-cloudrun/deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+			readmePath := filepath.Join(dir, "README.md")
+			readme := tc.readme(readTestFile(t, readmePath))
+			writeTestFile(t, readmePath, readme)
+
+			stdout, _, err := runGenerate(t, dir, "readme", "--check")
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("readme --check error = %v, want error: %t", err, tc.wantErr)
+			}
+			if tc.wantDiff != "" && !strings.Contains(stdout, tc.wantDiff) {
+				t.Errorf("readme --check printed:\n%s\nwant it to contain:\n%s", stdout, tc.wantDiff)
+			}
+			if got := readTestFile(t, readmePath); got != readme {
+				t.Errorf("readme --check wrote README.md:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestValidateNameLength(t *testing.T) {
+	name50 := "Deploy App " + strings.Repeat("x", 39)
+	name51 := name50 + "x"
+
+	cases := []struct {
+		name       string
+		propsName  string
+		args       []string
+		wantErrors []string
+	}{
+		{
+			name:       "50_characters",
+			propsName:  name50,
+			wantErrors: []string{},
+		},
+		{
+			name:      "51_characters",
+			propsName: name51,
+			wantErrors: []string{
+				`1. workflow deploy-app: properties name "` + name51 + `" is 51 characters, must be at most 50`,
+			},
+		},
+		{
+			name:       "51_characters_max_name_length",
+			propsName:  name51,
+			args:       []string{"--max-name-length", "51"},
+			wantErrors: []string{},
+		},
+		{
+			name:      "padded",
+			propsName: " Deploy App ",
+			wantErrors: []string{
+				`1. workflow deploy-app: properties name " Deploy App " must not have leading or trailing whitespace`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, map[string]string{
+				"properties/deploy-app.properties.json":    strings.Replace(testProperties(`["Cloud Run", "Deployment"]`), `"Deploy App"`, `"`+tc.propsName+`"`, 1),
+				"workflows/deploy-cloudrun/deploy-app.yml": strings.Replace(testWorkflowContents, "Deploy App", strings.TrimSpace(tc.propsName), 1),
+			})
+
+			stdout, _, err := runGenerate(t, dir, append([]string{"validate"}, tc.args...)...)
+			if len(tc.wantErrors) == 0 && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if len(tc.wantErrors) > 0 && err == nil {
+				t.Fatalf("validate succeeded, want %q", tc.wantErrors)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+		})
+	}
+}
+
+func TestReadmeOutputFormats(t *testing.T) {
+	cases := []struct {
+		format     string
+		readmePath string
+		want       []string
+	}{
+		{
+			format:     "md",
+			readmePath: "README.md",
+			want: []string{
+				"- [deploy-cloudrun](#deploy-cloudrun)\n",
+				"### [deploy-cloudrun](workflows/deploy-cloudrun/README.md)\n",
+				"|[deploy-app](workflows/deploy-cloudrun/deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |\n",
+			},
+		},
+		{
+			format:     "html",
+			readmePath: "README.html",
+			want: []string{
+				`<li><a href="#deploy-cloudrun">deploy-cloudrun</a></li>`,
+				`<h3 id="deploy-cloudrun"><a href="workflows/deploy-cloudrun/README.md">deploy-cloudrun</a></h3>`,
+				`<tr><td><a href="workflows/deploy-cloudrun/deploy-app.yml">deploy-app</a></td><td>✅</td><td>Deploy an app to Cloud Run.</td></tr>`,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.format, func(t *testing.T) {
+			t.Setenv("OUTPUT_FORMAT", tc.format)
+			dir := newTestRepo(t, nil)
+
+			if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			readme := readTestFile(t, filepath.Join(dir, tc.readmePath))
+			for _, want := range tc.want {
+				if !strings.Contains(readme, want) {
+					t.Errorf("readme wrote %s:\n%s\nwant it to contain:\n%s", tc.readmePath, readme, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadmeWorkflowOrder(t *testing.T) {
+	files := map[string]string{
+		"workflow.config.json": `{
+  "deploy-one": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-one.yml", "propertiesPath": "properties/deploy-one.properties.json"},
+  "deploy-two": {"starter": false, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-two.yml", "propertiesPath": "properties/deploy-two.properties.json"},
+  "deploy-three": {"starter": false, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-three.yml", "propertiesPath": "properties/deploy-three.properties.json"}
+}
+`,
+		"workflows/deploy-cloudrun/deploy-app.yml": "",
+		"properties/deploy-app.properties.json":    "",
+	}
+	for workflowID, name := range map[string]string{"deploy-one": "Zeta Deploy", "deploy-two": "alpha Deploy", "deploy-three": "Beta Deploy"} {
+		files["workflows/deploy-cloudrun/"+workflowID+".yml"] = strings.Replace(testWorkflowContents, "Deploy App", name, 1)
+		files["properties/"+workflowID+".properties.json"] = strings.Replace(testProperties(`["Cloud Run", "Deployment"]`), "Deploy App", name, 1)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "by_name",
+			args: []string{"readme"},
+			want: []string{"deploy-two", "deploy-three", "deploy-one"},
+		},
+		{
+			name: "starter_first",
+			args: []string{"readme", "--starter-first"},
+			want: []string{"deploy-one", "deploy-two", "deploy-three"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, files)
+
+			if _, _, err := runGenerate(t, dir, tc.args...); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			got := make([]string, 0)
+			for _, line := range strings.Split(readTestFile(t, filepath.Join(dir, "README.md")), "\n") {
+				if strings.HasPrefix(line, "|[") {
+					got = append(got, strings.TrimPrefix(strings.SplitN(line, "]", 2)[0], "|["))
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("readme listed %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowFrom(t *testing.T) {
+	cases := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "cloned",
+			args: []string{"workflow", "--from", "deploy-app", "deploy-cloudrun/deploy-clone"},
+		},
+		{
+			name:    "missing_source",
+			args:    []string{"workflow", "--from", "deploy-missing", "deploy-cloudrun/deploy-clone"},
+			wantErr: "workflow deploy-missing to copy from does not exist in workflow.config.json",
+		},
+		{
+			name:    "existing_workflow",
+			args:    []string{"workflow", "--from", "deploy-app", "deploy-cloudrun/deploy-app"},
+			wantErr: "workflow exists in workflow.config.json, please use existing workflow or use a different name",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			before := snapshotFiles(t, dir)
+
+			_, _, err := runGenerate(t, dir, tc.args...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("workflow --from error = %v, want %s", err, tc.wantErr)
+				}
+				if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+					t.Errorf("workflow --from changed the files of %s after failing", dir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("workflow --from: %s", err)
+			}
+
+			after := snapshotFiles(t, dir)
+			if got, want := after["workflows/deploy-cloudrun/deploy-clone.yml"], testWorkflowContents; got != want {
+				t.Errorf("workflow --from wrote:\n%s\nwant the source workflow:\n%s", got, want)
+			}
+
+			var properties examples.PropertiesConfig
+			if err := json.Unmarshal([]byte(after["properties/deploy-clone.properties.json"]), &properties); err != nil {
+				t.Fatalf("failed to unmarshal properties: %s", err)
+			}
+			want := examples.PropertiesConfig{
+				Name:        "Deploy Clone",
+				Description: "deploy-clone - A new deployments workflow template.",
+				Creator:     "Google Cloud",
+				IconName:    "google-cloud",
+				Categories:  []string{"Cloud Run", "Deployment"},
+			}
+			if !reflect.DeepEqual(properties, want) {
+				t.Errorf("workflow --from wrote properties %+v, want %+v", properties, want)
+			}
+
+			var wc examples.WorkflowConfig
+			if err := json.Unmarshal([]byte(after["workflow.config.json"]), &wc); err != nil {
+				t.Fatalf("failed to unmarshal config: %s", err)
+			}
+			wantWorkflow := examples.Workflow{
+				Type:           "deployments",
+				WorkflowPath:   "workflows/deploy-cloudrun/deploy-clone.yml",
+				PropertiesPath: "properties/deploy-clone.properties.json",
+			}
+			if got := wc["deploy-clone"]; got != wantWorkflow {
+				t.Errorf("workflow --from wrote config entry %+v, want %+v", got, wantWorkflow)
+			}
+		})
+	}
+}
+
+func TestValidateOrphans(t *testing.T) {
+	cases := []struct {
+		name       string
+		files      map[string]string
+		wantErrors []string
+	}{
+		{
+			name:  "properties_file",
+			files: map[string]string{"properties/deploy-old.properties.json": testProperties(`["Cloud Run", "Deployment"]`)},
+			wantErrors: []string{
+				"1. orphaned file properties/deploy-old.properties.json is not referenced by any workflow in workflow.config.json",
+			},
+		},
+		{
+			name:  "workflow_file",
+			files: map[string]string{"workflows/deploy-cloudrun/deploy-old.yml": testWorkflowContents},
+			wantErrors: []string{
+				"1. orphaned file workflows/deploy-cloudrun/deploy-old.yml is not referenced by any workflow in workflow.config.json",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+
+			stdout, _, err := runGenerate(t, dir, "validate")
+			if err == nil || err.Error() != "found 1 problem(s) in workflow.config.json" {
+				t.Fatalf("validate error = %v, want found 1 problem(s) in workflow.config.json", err)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+		})
+	}
+}
+
+func TestReadmeVerbose(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		wantLogs []string
+	}{
+		{
+			name:     "quiet",
+			args:     []string{"readme"},
+			wantLogs: []string{},
+		},
+		{
+			name: "verbose",
+			args: []string{"readme", "--verbose"},
+			wantLogs: []string{
+				"validating workflow workflows/deploy-cloudrun/deploy-app.yml",
+				"loaded properties file properties/deploy-app.properties.json",
+				"rendering template " + filepath.Join(testTemplateDir, "README.tmpl.md") + " to README.md",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+
+			_, logs, err := runGenerate(t, dir, tc.args...)
+			if err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("readme logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestValidateTriggers(t *testing.T) {
+	cases := []struct {
+		name       string
+		on         string
+		files      map[string]string
+		wantErrors []string
+	}{
+		{
+			name:       "map",
+			on:         "on:\n  push:\n    branches:\n      - main\n  workflow_dispatch:\n",
+			wantErrors: []string{},
+		},
+		{
+			name:       "list",
+			on:         "on: [push, pull_request]\n",
+			wantErrors: []string{},
+		},
+		{
+			name:       "scalar",
+			on:         "on: workflow_dispatch\n",
+			wantErrors: []string{},
+		},
+		{
+			name: "disallowed_map",
+			on:   "on:\n  push:\n  issue_comment:\n",
+			wantErrors: []string{
+				`1. workflow deploy-app: invalid workflow workflows/deploy-cloudrun/deploy-app.yml: trigger "issue_comment" is not allowed, see triggers.json or the default triggers for allowed values`,
+			},
+		},
+		{
+			name: "disallowed_list",
+			on:   "on: [push, issue_comment]\n",
+			wantErrors: []string{
+				`1. workflow deploy-app: invalid workflow workflows/deploy-cloudrun/deploy-app.yml: trigger "issue_comment" is not allowed, see triggers.json or the default triggers for allowed values`,
+			},
+		},
+		{
+			name:       "triggers_file",
+			on:         "on: [push, issue_comment]\n",
+			files:      map[string]string{"triggers.json": `["push", "issue_comment"]`},
+			wantErrors: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"workflows/deploy-cloudrun/deploy-app.yml": strings.Replace(testWorkflowContents, "on:\n  push:\n    branches:\n      - main\n", tc.on, 1),
+			}
+			for name, contents := range tc.files {
+				files[name] = contents
+			}
+			dir := newTestRepo(t, files)
+
+			stdout, _, err := runGenerate(t, dir, "validate", "--check-triggers")
+			if len(tc.wantErrors) == 0 && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if len(tc.wantErrors) > 0 && err == nil {
+				t.Fatalf("validate succeeded, want %q", tc.wantErrors)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+		})
+	}
+}
+
+func TestReadmeConfigDir(t *testing.T) {
+	cases := []struct {
+		name       string
+		outputPath string // OUTPUT_PATH, unset when empty
+		wantReadme string // relative to the config directory, or to the working directory when OUTPUT_PATH is set
+	}{
+		{
+			name:       "next_to_config",
+			wantReadme: "README.md",
+		},
+		{
+			name:       "output_path",
+			outputPath: "docs/index.md",
+			wantReadme: "docs/index.md",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			configDir := t.TempDir()
+			for name, contents := range testRepoFiles() {
+				writeTestFile(t, filepath.Join(configDir, name), contents)
+			}
+			workingDir := t.TempDir()
+			if tc.outputPath != "" {
+				t.Setenv("OUTPUT_PATH", tc.outputPath)
+				writeTestFile(t, filepath.Join(workingDir, tc.outputPath), "")
+			}
+
+			if _, _, err := runGenerate(t, workingDir, "readme", "--config", filepath.Join(configDir, "workflow.config.json")); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			readmePath := filepath.Join(configDir, tc.wantReadme)
+			if tc.outputPath != "" {
+				readmePath = filepath.Join(workingDir, tc.wantReadme)
+				if got := readTestFile(t, filepath.Join(configDir, "README.md")); got != "" {
+					t.Errorf("readme wrote the README next to the config with OUTPUT_PATH set:\n%s", got)
+				}
+			}
+
+			readme := readTestFile(t, readmePath)
+			for _, want := range []string{
+				"### [deploy-cloudrun](workflows/deploy-cloudrun/README.md)\n",
+				"|[deploy-app](workflows/deploy-cloudrun/deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |\n",
+			} {
+				if !strings.Contains(readme, want) {
+					t.Errorf("readme wrote %s:\n%s\nwant it to contain:\n%s", tc.wantReadme, readme, want)
+				}
+			}
+
+			// rewriting the config keeps its paths relative to its directory
+			if _, _, err := runGenerate(t, workingDir, "fmt-config", "--config", filepath.Join(configDir, "workflow.config.json")); err != nil {
+				t.Fatalf("fmt-config: %s", err)
+			}
+			if got := readTestFile(t, filepath.Join(configDir, "workflow.config.json")); got != testConfig {
+				t.Errorf("fmt-config wrote:\n%s\nwant:\n%s", got, testConfig)
+			}
+		})
+	}
+}
+
+func TestWorkflowType(t *testing.T) {
+	cases := []struct {
+		name         string
+		workflowType string
+		wantErr      string
+	}{
+		{
+			name:         "valid",
+			workflowType: "deployments",
+		},
+		{
+			name:         "valid_subdirectory",
+			workflowType: "ci/go",
+		},
+		{
+			name:         "invalid",
+			workflowType: "deploymentz",
+			wantErr:      `invalid type "deploymentz", expected one of automation, ci, code-scanning, deployments, optionally followed by /subdirectory`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+
+			_, _, err := runGenerate(t, dir, "workflow", "--type", tc.workflowType, "deploy-cloudrun/deploy-new")
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("workflow error = %v, want %s", err, tc.wantErr)
+				}
+				if got := readTestFile(t, filepath.Join(dir, "workflow.config.json")); got != testConfig {
+					t.Errorf("workflow wrote config for an invalid type:\n%s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("workflow: %s", err)
+			}
+
+			var wc examples.WorkflowConfig
+			if err := json.Unmarshal([]byte(readTestFile(t, filepath.Join(dir, "workflow.config.json"))), &wc); err != nil {
+				t.Fatal(err)
+			}
+			if got := wc["deploy-new"].Type; got != tc.workflowType {
+				t.Errorf("workflow wrote type %q, want %q", got, tc.workflowType)
+			}
+		})
+	}
+}
+
+func TestReadmeSummary(t *testing.T) {
+	// two actions with three workflows, two of them starters
+	files := map[string]string{
+		"workflow.config.json": `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-other": {"starter": false, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-other.yml", "propertiesPath": "properties/deploy-other.properties.json"},
+  "auth-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/auth/auth-app.yml", "propertiesPath": "properties/auth-app.properties.json"}
+}
+`,
+		"workflows/auth/README.md":            "# auth\n",
+		"workflows/auth/auth-app.yml":         strings.Replace(testWorkflowContents, "Deploy App", "Auth App", 1),
+		"properties/auth-app.properties.json": `{"name": "Auth App", "description": "Authenticate an app to Google Cloud.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Deployment"]}` + "\n",
+	}
+	for name, contents := range testSecondWorkflowFiles {
+		if _, ok := files[name]; !ok {
+			files[name] = contents
+		}
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "text",
+			want: "Generated README.md for 2 actions, 3 workflows (2 starters)\n",
+		},
+		{
+			name: "json",
+			args: []string{"--json"},
+			want: "{\n  \"actions\": 2,\n  \"workflows\": 3,\n  \"starters\": 2\n}\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, files)
+
+			stdout, _, err := runGenerate(t, dir, append([]string{"readme"}, tc.args...)...)
+			if err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+			if stdout != tc.want {
+				t.Errorf("readme printed %q, want %q", stdout, tc.want)
+			}
+		})
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	cases := []struct {
+		name    string
+		files   map[string]string
+		wantFix string
+	}{
+		{
+			name: "too_short_path",
+			files: map[string]string{
+				"workflow.config.json":     strings.Replace(testConfig, "workflows/deploy-cloudrun/deploy-app.yml", "workflows/deploy-app.yml", 1),
+				"workflows/deploy-app.yml": testWorkflowContents,
+			},
+			wantFix: "  fix:     workflow path must be workflows/<action>/<name>.yml; you provided workflows/deploy-app.yml\n",
+		},
+		{
+			name:    "missing_properties_file",
+			files:   map[string]string{"properties/deploy-app.properties.json": ""},
+			wantFix: "  fix:     create properties/deploy-app.properties.json with a name and description, or fix the propertiesPath of deploy-app in workflow.config.json\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+
+			stdout, _, err := runGenerate(t, dir, "doctor")
+			if err == nil || !strings.HasPrefix(err.Error(), "found ") || !strings.HasSuffix(err.Error(), " problem(s) in workflow.config.json") {
+				t.Errorf("doctor error = %v, want found N problem(s) in workflow.config.json", err)
+			}
+			if !strings.HasPrefix(stdout, "workflow deploy-app:\n") {
+				t.Errorf("doctor printed:\n%s\nwant the problems grouped under workflow deploy-app", stdout)
+			}
+			if !strings.Contains(stdout, tc.wantFix) {
+				t.Errorf("doctor printed:\n%s\nwant it to contain:\n%s", stdout, tc.wantFix)
+			}
+		})
+	}
+}
+
+func TestValidateDescription(t *testing.T) {
+	lowercaseProperties := strings.Replace(testProperties(`["Cloud Run", "Deployment"]`), "Deploy an app to Cloud Run.", "deploy an app to Cloud Run", 1)
+
+	cases := []struct {
+		name       string
+		files      map[string]string
+		args       []string
+		wantErr    string
+		wantErrors []string
+		wantLogs   []string
+	}{
+		{
+			name:       "compliant",
+			wantErrors: []string{},
+			wantLogs:   []string{},
+		},
+		{
+			name:       "lowercase_unpunctuated",
+			files:      map[string]string{"properties/deploy-app.properties.json": lowercaseProperties},
+			wantErrors: []string{},
+			wantLogs: []string{
+				`warning: workflow deploy-app: properties description "deploy an app to Cloud Run" should start with an uppercase letter`,
+				`warning: workflow deploy-app: properties description "deploy an app to Cloud Run" should end with '.', '!' or '?'`,
+			},
+		},
+		{
+			name:    "lowercase_unpunctuated_strict",
+			files:   map[string]string{"properties/deploy-app.properties.json": lowercaseProperties},
+			args:    []string{"--strict"},
+			wantErr: "found 2 problem(s) in workflow.config.json",
+			wantErrors: []string{
+				`1. workflow deploy-app: properties description "deploy an app to Cloud Run" should start with an uppercase letter`,
+				`2. workflow deploy-app: properties description "deploy an app to Cloud Run" should end with '.', '!' or '?'`,
+			},
+			wantLogs: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+
+			stdout, logs, err := runGenerate(t, dir, append([]string{"validate"}, tc.args...)...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("validate error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("validate logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestWorkflowForce(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		wantErr  string
+		wantLogs []string
+	}{
+		{
+			name:    "without_force",
+			wantErr: "workflow exists in workflow.config.json, please use existing workflow or use a different name",
+		},
+		{
+			name: "force",
+			args: []string{"--force"},
+			wantLogs: []string{
+				"warning: replacing the entry for workflow deploy-new in workflow.config.json",
+				"warning: overwriting workflow file workflows/deploy-cloudrun/deploy-new.yml",
+				"warning: overwriting properties file properties/deploy-new.properties.json",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+
+			if _, _, err := runGenerate(t, dir, "workflow", "--force", "deploy-cloudrun/deploy-new"); err != nil {
+				t.Fatalf("first workflow: %s", err)
+			}
+			workflowPath := filepath.Join(dir, "workflows", "deploy-cloudrun", "deploy-new.yml")
+			propertiesPath := filepath.Join(dir, "properties", "deploy-new.properties.json")
+			wantWorkflow, wantProperties := readTestFile(t, workflowPath), readTestFile(t, propertiesPath)
+			writeTestFile(t, workflowPath, "# edited\n")
+			writeTestFile(t, propertiesPath, "{}\n")
+			before := snapshotFiles(t, dir)
+
+			_, logs, err := runGenerate(t, dir, append(append([]string{"workflow"}, tc.args...), "--starter", "deploy-cloudrun/deploy-new")...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("second workflow error = %v, want %s", err, tc.wantErr)
+				}
+				if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+					t.Errorf("second workflow without --force changed files")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("second workflow: %s", err)
+			}
+
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("second workflow logged %q, want %q", got, tc.wantLogs)
+			}
+			if got := readTestFile(t, workflowPath); got != wantWorkflow {
+				t.Errorf("second workflow wrote workflow file:\n%s\nwant:\n%s", got, wantWorkflow)
+			}
+			if got := readTestFile(t, propertiesPath); got != wantProperties {
+				t.Errorf("second workflow wrote properties file:\n%s\nwant:\n%s", got, wantProperties)
+			}
+
+			var wc examples.WorkflowConfig
+			if err := json.Unmarshal([]byte(readTestFile(t, filepath.Join(dir, "workflow.config.json"))), &wc); err != nil {
+				t.Fatal(err)
+			}
+			if !wc["deploy-new"].Starter {
+				t.Errorf("second workflow did not update the config entry to a starter workflow")
+			}
+		})
+	}
+}
+
+func TestReadmeActionReadmes(t *testing.T) {
+	edited := "# deploy-cloudrun\n\nEdited by hand.\n"
+	rendered := `# deploy-cloudrun examples
+
+` + "`Cloud Run` `Deployment`" + `
+
+| Name | Starter | Description |
+| ---- | ------- | ----------- |
+| [Deploy App](deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |
+| [Deploy Other](deploy-other.yml) |  | Deploy another app to Cloud Run. |
+`
+
+	cases := []struct {
+		name  string
+		files map[string]string
+		args  []string
+		want  string
+	}{
+		{
+			name:  "missing_regenerated",
+			files: map[string]string{"workflows/deploy-cloudrun/README.md": ""},
+			args:  []string{"--regenerate-action-readmes"},
+			want:  rendered,
+		},
+		{
+			name:  "edited",
+			files: map[string]string{"workflows/deploy-cloudrun/README.md": edited},
+			want:  edited,
+		},
+		{
+			name:  "edited_regenerated",
+			files: map[string]string{"workflows/deploy-cloudrun/README.md": edited},
+			args:  []string{"--regenerate-action-readmes"},
+			want:  rendered,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{}
+			for name, contents := range testSecondWorkflowFiles {
+				files[name] = contents
+			}
+			for name, contents := range tc.files {
+				files[name] = contents
+			}
+			dir := newTestRepo(t, files)
+
+			if _, _, err := runGenerate(t, dir, append([]string{"readme"}, tc.args...)...); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			if got := readTestFile(t, filepath.Join(dir, "workflows", "deploy-cloudrun", "README.md")); got != tc.want {
+				t.Errorf("readme wrote action README:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFmtConfig(t *testing.T) {
+	messyConfig := `{"deploy-other": {"propertiesPath": "properties/deploy-other.properties.json", "workflowPath": "workflows/deploy-cloudrun/deploy-other.yml",
+"type": "deployments", "starter": false},
+    "deploy-app": {
+        "type": "deployments", "propertiesPath": "properties/deploy-app.properties.json",
+        "starter": true, "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml"
+    }}`
+	canonicalConfig := `{
+  "deploy-app": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml",
+    "propertiesPath": "properties/deploy-app.properties.json"
+  },
+  "deploy-other": {
+    "starter": false,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-cloudrun/deploy-other.yml",
+    "propertiesPath": "properties/deploy-other.properties.json"
+  }
+}
+`
+
+	cases := []struct {
+		name       string
+		config     string
+		args       []string
+		wantErr    string
+		wantConfig string
+		wantLogs   []string
+	}{
+		{
+			name:       "messy",
+			config:     messyConfig,
+			wantConfig: canonicalConfig,
+			wantLogs:   []string{"formatted workflow.config.json"},
+		},
+		{
+			name:       "canonical",
+			config:     canonicalConfig,
+			wantConfig: canonicalConfig,
+			wantLogs:   []string{},
+		},
+		{
+			name:       "messy_check",
+			config:     messyConfig,
+			args:       []string{"--check"},
+			wantErr:    "workflow.config.json is not formatted, run 'go run scripts/generate.go fmt-config' to format it",
+			wantConfig: messyConfig,
+			wantLogs:   []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"workflow.config.json": tc.config}
+			for name, contents := range testSecondWorkflowFiles {
+				if name != "workflow.config.json" {
+					files[name] = contents
+				}
+			}
+			dir := newTestRepo(t, files)
+
+			_, logs, err := runGenerate(t, dir, append([]string{"fmt-config"}, tc.args...)...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("fmt-config: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("fmt-config error = %v, want %s", err, tc.wantErr)
+			}
+			if got := readTestFile(t, filepath.Join(dir, "workflow.config.json")); got != tc.wantConfig {
+				t.Errorf("fmt-config wrote:\n%s\nwant:\n%s", got, tc.wantConfig)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("fmt-config logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestValidateConfigPaths(t *testing.T) {
+	cases := []struct {
+		name         string
+		workflowPath string // in the config, with {dir} replaced by the repository directory
+		wantErrors   []string
+	}{
+		{
+			name:         "relative",
+			workflowPath: "workflows/deploy-cloudrun/deploy-app.yml",
+			wantErrors:   []string{},
+		},
+		{
+			name:         "absolute",
+			workflowPath: "{dir}/workflows/deploy-cloudrun/deploy-app.yml",
+			wantErrors: []string{
+				"1. workflow deploy-app: invalid workflow path: path {dir}/workflows/deploy-cloudrun/deploy-app.yml must be relative to the directory of workflow.config.json",
+				"2. orphaned file workflows/deploy-cloudrun/deploy-app.yml is not referenced by any workflow in workflow.config.json",
+			},
+		},
+		{
+			name:         "escaping",
+			workflowPath: "workflows/../../deploy-cloudrun/deploy-app.yml",
+			wantErrors: []string{
+				"1. workflow deploy-app: invalid workflow path: path workflows/../../deploy-cloudrun/deploy-app.yml must not contain .., it should stay under the directory of workflow.config.json",
+				"2. orphaned file workflows/deploy-cloudrun/deploy-app.yml is not referenced by any workflow in workflow.config.json",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			dirPath := filepath.ToSlash(dir)
+			writeTestFile(t, filepath.Join(dir, "workflow.config.json"), strings.Replace(testConfig, "workflows/deploy-cloudrun/deploy-app.yml", strings.ReplaceAll(tc.workflowPath, "{dir}", dirPath), 1))
+
+			stdout, _, err := runGenerate(t, dir, "validate")
+			wantErr := ""
+			if len(tc.wantErrors) > 0 {
+				wantErr = fmt.Sprintf("found %d problem(s) in workflow.config.json", len(tc.wantErrors))
+			}
+			if (err == nil && wantErr != "") || (err != nil && err.Error() != wantErr) {
+				t.Errorf("validate error = %v, want %q", err, wantErr)
+			}
+
+			wantErrors := make([]string, 0, len(tc.wantErrors))
+			for _, wantError := range tc.wantErrors {
+				wantErrors = append(wantErrors, strings.ReplaceAll(wantError, "{dir}", dirPath))
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, wantErrors)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	files := map[string]string{
+		"properties/deploy-other.properties.json": testProperties(`["Cloud Run", "Serverless"]`),
+	}
+	for name, contents := range testSecondWorkflowFiles {
+		if _, ok := files[name]; !ok {
+			files[name] = contents
+		}
+	}
+
+	t.Run("text", func(t *testing.T) {
+		dir := newTestRepo(t, files)
+
+		stdout, _, err := runGenerate(t, dir, "stats")
+		if err != nil {
+			t.Fatalf("stats: %s", err)
+		}
+
+		want := `TYPE         WORKFLOWS
+deployments  2
+
+CATEGORY    WORKFLOWS
+Cloud Run   2
+Deployment  1
+Serverless  1
+
+STARTER  WORKFLOWS
+true     1
+false    1
+`
+		if stdout != want {
+			t.Errorf("stats printed:\n%s\nwant:\n%s", stdout, want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		dir := newTestRepo(t, files)
+
+		stdout, _, err := runGenerate(t, dir, "stats", "--json")
+		if err != nil {
+			t.Fatalf("stats: %s", err)
+		}
+
+		var got workflowStats
+		if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+			t.Fatalf("stats printed invalid JSON %q: %s", stdout, err)
+		}
+		want := workflowStats{
+			Types:       map[string]int{"deployments": 2},
+			Categories:  map[string]int{"Cloud Run": 2, "Deployment": 1, "Serverless": 1},
+			Starters:    1,
+			NonStarters: 1,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("stats printed %+v, want %+v", got, want)
+		}
+	})
+}
+
+func TestWorkflowDiff(t *testing.T) {
+	wantDiff := `+   },
++   "deploy-new": {
++     "starter": false,
++     "type": "deployments",
++     "workflowPath": "workflows/deploy-cloudrun/deploy-new.yml",
++     "propertiesPath": "properties/deploy-new.properties.json"
+`
+
+	cases := []struct {
+		name        string
+		args        []string
+		wantWritten bool
+	}{
+		{
+			name:        "diff",
+			args:        []string{"--diff"},
+			wantWritten: true,
+		},
+		{
+			name: "diff_dry_run",
+			args: []string{"--diff", "--dry-run"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+
+			stdout, _, err := runGenerate(t, dir, append(append([]string{"workflow"}, tc.args...), "deploy-cloudrun/deploy-new")...)
+			if err != nil {
+				t.Fatalf("workflow: %s", err)
+			}
+
+			if !strings.Contains(stdout, "--- workflow.config.json\n+++ workflow.config.json\n") || !strings.Contains(stdout, wantDiff) {
+				t.Errorf("workflow printed:\n%s\nwant the diff to contain:\n%s", stdout, wantDiff)
+			}
+
+			config := readTestFile(t, filepath.Join(dir, "workflow.config.json"))
+			if written := strings.Contains(config, `"deploy-new"`); written != tc.wantWritten {
+				t.Errorf("workflow wrote the config entry: %t, want %t", written, tc.wantWritten)
+			}
+		})
+	}
+}
+
+func TestReadmeConfigOrder(t *testing.T) {
+	files := map[string]string{
+		"workflow.config.json": `{
+  "auth-app": {"starter": false, "type": "deployments", "workflowPath": "workflows/auth/auth-app.yml", "propertiesPath": "properties/auth-app.properties.json"},
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "setup-app": {"starter": false, "type": "deployments", "workflowPath": "workflows/setup-gcloud/setup-app.yml", "propertiesPath": "properties/setup-app.properties.json"}
+}
+`,
+		"workflows/auth/README.md":             "# auth\n",
+		"workflows/auth/auth-app.yml":          strings.Replace(testWorkflowContents, "Deploy App", "Auth App", 1),
+		"properties/auth-app.properties.json":  strings.Replace(testProperties(`["Deployment"]`), "Deploy App", "Auth App", 1),
+		"workflows/setup-gcloud/README.md":     "# setup-gcloud\n",
+		"workflows/setup-gcloud/setup-app.yml": strings.Replace(testWorkflowContents, "Deploy App", "Setup App", 1),
+		"properties/setup-app.properties.json": strings.Replace(testProperties(`["Deployment"]`), "Deploy App", "Setup App", 1),
+	}
+
+	cases := []struct {
+		name         string
+		readmeConfig string
+		wantTitle    string
+		wantActions  []string
+	}{
+		{
+			name:        "default",
+			wantTitle:   "## Available Examples\n",
+			wantActions: []string{"auth", "deploy-cloudrun", "setup-gcloud"},
+		},
+		{
+			name:         "partial_order",
+			readmeConfig: `{"order": ["setup-gcloud"], "sectionTitle": "Getting Started"}`,
+			wantTitle:    "## Getting Started\n",
+			wantActions:  []string{"setup-gcloud", "auth", "deploy-cloudrun"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			repoFiles := map[string]string{"readme.config.json": tc.readmeConfig}
+			for name, contents := range files {
+				repoFiles[name] = contents
+			}
+			dir := newTestRepo(t, repoFiles)
+
+			if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			readme := readTestFile(t, filepath.Join(dir, "README.md"))
+			if !strings.Contains(readme, tc.wantTitle) {
+				t.Errorf("readme wrote:\n%s\nwant the section title %q", readme, tc.wantTitle)
+			}
+
+			got := make([]string, 0)
+			for _, line := range strings.Split(readme, "\n") {
+				if strings.HasPrefix(line, "### [") {
+					got = append(got, strings.SplitN(strings.TrimPrefix(line, "### ["), "]", 2)[0])
+				}
+			}
+			if !reflect.DeepEqual(got, tc.wantActions) {
+				t.Errorf("readme listed actions %q, want %q", got, tc.wantActions)
+			}
+		})
+	}
+}
+
+func TestReadmeDeprecated(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{
+		"properties/deploy-app.properties.json": `{"name": "Deploy App", "description": "Deploy an app to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run", "Deployment"], "deprecated": true, "deprecationNote": "Use deploy-other instead."}` + "\n",
+	})
+
+	if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+		t.Fatalf("readme: %s", err)
+	}
+
+	readme := readTestFile(t, filepath.Join(dir, "README.md"))
+	want := "|~~[deploy-app](workflows/deploy-cloudrun/deploy-app.yml)~~ (deprecated) | ✅ | Deploy an app to Cloud Run. **Deprecated:** Use deploy-other instead. |\n"
+	if !strings.Contains(readme, want) {
+		t.Errorf("readme wrote:\n%s\nwant it to contain:\n%s", readme, want)
+	}
+}
+
+func TestValidateFailOnWarning(t *testing.T) {
+	files := map[string]string{
+		"properties/deploy-app.properties.json": strings.Replace(testProperties(`["Cloud Run", "Deployment"]`), "Deploy an app to Cloud Run.", "Deploy an app to Cloud Run", 1),
+	}
+	wantLogs := []string{`warning: workflow deploy-app: properties description "Deploy an app to Cloud Run" should end with '.', '!' or '?'`}
+
+	cases := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "warnings_only",
+		},
+		{
+			name:    "warnings_only_fail_on_warning",
+			args:    []string{"--fail-on-warning"},
+			wantErr: "found 1 problem(s) in workflow.config.json",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, files)
+
+			_, logs, err := runGenerate(t, dir, append([]string{"validate"}, tc.args...)...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("validate error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, wantLogs) {
+				t.Errorf("validate logged %q, want %q", got, wantLogs)
+			}
+		})
+	}
+}
+
+func TestValidateOnlyChanged(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skipf("git is not available: %s", err)
+	}
+
+	invalidProperties := testProperties(`["Cloud Run", "Deployements"]`)
+	wantErrors := []string{
+		`1. workflow deploy-app: invalid category "Deployements", see categories.json or the default categories for allowed values`,
+	}
+
+	cases := []struct {
+		name       string
+		args       []string
+		modify     bool
+		wantErrors []string
+		wantErr    string
+	}{
+		{
+			name:       "unchanged",
+			args:       []string{"--only-changed", "HEAD"},
+			wantErrors: []string{},
+		},
+		{
+			name:       "changed",
+			args:       []string{"--only-changed", "HEAD"},
+			modify:     true,
+			wantErrors: wantErrors,
+		},
+		{
+			// --only-changed is an alias of --since
+			name:       "changed_since",
+			args:       []string{"--since", "HEAD"},
+			modify:     true,
+			wantErrors: wantErrors,
+		},
+		{
+			name:       "same_ref",
+			args:       []string{"--since", "HEAD", "--only-changed", "HEAD"},
+			wantErrors: []string{},
+		},
+		{
+			name:    "different_refs",
+			args:    []string{"--since", "main", "--only-changed", "HEAD"},
+			wantErr: "--since main and --only-changed HEAD must not both be set",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			// the invalid properties are committed, so they are only reported once changed
+			dir := newTestRepo(t, map[string]string{"properties/deploy-app.properties.json": invalidProperties})
+			git := func(args ...string) {
+				t.Helper()
+				cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+				cmd.Dir = dir
+				if output, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, output)
+				}
+			}
+			git("init", "--quiet")
+			git("add", ".")
+			git("commit", "--quiet", "-m", "initial")
+
+			if tc.modify {
+				writeTestFile(t, filepath.Join(dir, "properties/deploy-app.properties.json"), strings.Replace(invalidProperties, "Deploy App", "Deploy the App", 1))
+			}
+
+			stdout, _, err := runGenerate(t, dir, append([]string{"validate"}, tc.args...)...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("validate error = %v, want %s", err, tc.wantErr)
+				}
+				return
+			}
+			if len(tc.wantErrors) == 0 && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if len(tc.wantErrors) > 0 && err == nil {
+				t.Fatalf("validate succeeded, want %q", tc.wantErrors)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+		})
+	}
+}
+
+func TestValidateStarterPerAction(t *testing.T) {
+	cases := []struct {
+		name       string
+		files      map[string]string
+		args       []string
+		wantErr    string
+		wantErrors []string
+		wantLogs   []string
+	}{
+		{
+			name:       "mixed",
+			files:      testSecondWorkflowFiles,
+			wantErrors: []string{},
+			wantLogs:   []string{},
+		},
+		{
+			name: "only_non_starters",
+			files: map[string]string{
+				"workflow.config.json": strings.Replace(testConfig, `"starter": true`, `"starter": false`, 1),
+			},
+			wantErrors: []string{},
+			wantLogs:   []string{"warning: no starter workflow in action deploy-cloudrun, which has 1 workflow(s) and will not appear in the starter workflows gallery"},
+		},
+		{
+			name: "only_non_starters_strict",
+			files: map[string]string{
+				"workflow.config.json": strings.Replace(testConfig, `"starter": true`, `"starter": false`, 1),
+			},
+			args:       []string{"--strict"},
+			wantErr:    "found 1 problem(s) in workflow.config.json",
+			wantErrors: []string{"1. no starter workflow in action deploy-cloudrun, which has 1 workflow(s) and will not appear in the starter workflows gallery"},
+			wantLogs:   []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+
+			stdout, logs, err := runGenerate(t, dir, append([]string{"validate"}, tc.args...)...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("validate error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantErrors) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantErrors)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("validate logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestSetWorkflowField(t *testing.T) {
+	files := map[string]string{
+		"workflow.config.json": `{
+  "auth-other": {"starter": false, "type": "deployments", "workflowPath": "workflows/auth/auth-other.yml", "propertiesPath": "properties/auth-other.properties.json"},
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-other": {"starter": false, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-other.yml", "propertiesPath": "properties/deploy-other.properties.json"}
+}
+`,
+	}
+
+	cases := []struct {
+		name        string
+		args        []string
+		wantErr     string
+		wantStarter map[string]bool
+		wantLogs    []string
+	}{
+		{
+			name:        "glob",
+			args:        []string{"set", "starter", "true", "deploy-*"},
+			wantStarter: map[string]bool{"auth-other": false, "deploy-app": true, "deploy-other": true},
+			wantLogs:    []string{"set starter=true for workflow deploy-other"},
+		},
+		{
+			name:        "all",
+			args:        []string{"set", "starter", "true"},
+			wantStarter: map[string]bool{"auth-other": true, "deploy-app": true, "deploy-other": true},
+			wantLogs:    []string{"set starter=true for workflow auth-other", "set starter=true for workflow deploy-other"},
+		},
+		{
+			name:        "invalid_value",
+			args:        []string{"set", "starter", "yes", "deploy-*"},
+			wantErr:     `invalid starter value "yes", expected true or false`,
+			wantStarter: map[string]bool{"auth-other": false, "deploy-app": true, "deploy-other": false},
+			wantLogs:    []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, files)
+
+			_, logs, err := runGenerate(t, dir, tc.args...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("set: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("set error = %v, want %s", err, tc.wantErr)
+			}
+
+			var wc examples.WorkflowConfig
+			if err := json.Unmarshal([]byte(readTestFile(t, filepath.Join(dir, "workflow.config.json"))), &wc); err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]bool{}
+			for workflowID, workflow := range wc {
+				got[workflowID] = workflow.Starter
+			}
+			if !reflect.DeepEqual(got, tc.wantStarter) {
+				t.Errorf("set wrote starter values %v, want %v", got, tc.wantStarter)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("set logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestTemplateDir(t *testing.T) {
+	customTemplates := map[string]string{
+		"README.tmpl.md":                "# Custom README\n{{range .Actions}}\n- {{.Name}}\n{{end}}",
+		"action-README.tmpl.md":         "# {{.Name}}\n",
+		"workflow.properties.tmpl.json": "{}\n",
+	}
+
+	cases := []struct {
+		name       string
+		templates  map[string]string
+		args       []string
+		wantErr    string
+		wantReadme string
+	}{
+		{
+			name:       "custom",
+			templates:  customTemplates,
+			args:       []string{"readme"},
+			wantReadme: "# Custom README\n\n- deploy-cloudrun\n",
+		},
+		{
+			name:      "missing_templates",
+			templates: map[string]string{"README.tmpl.md": customTemplates["README.tmpl.md"]},
+			args:      []string{"workflow", "deploy-cloudrun/deploy-new"},
+			wantErr:   "template directory %s is missing required templates: workflow.properties.tmpl.json, action-README.tmpl.md",
+		},
+		{
+			name:    "missing_dir",
+			args:    []string{"readme"},
+			wantErr: "failed to find template directory %s: stat %[1]s: no such file or directory",
+		},
+		{
+			name: "unused_templates",
+			args: []string{"validate"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, testRepoFiles())
+			templateDir := filepath.Join(t.TempDir(), "templates")
+			for name, contents := range tc.templates {
+				writeTestFile(t, filepath.Join(templateDir, name), contents)
+			}
+
+			_, _, err := runGenerate(t, dir, append([]string{"--template-dir=" + templateDir}, tc.args...)...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("%s: %s", tc.args[0], err)
+			}
+			if wantErr := fmt.Sprintf(tc.wantErr, templateDir); tc.wantErr != "" && (err == nil || err.Error() != wantErr) {
+				t.Fatalf("%s error = %v, want %s", tc.args[0], err, wantErr)
+			}
+
+			if tc.wantReadme != "" {
+				if got := readTestFile(t, filepath.Join(dir, "README.md")); got != tc.wantReadme {
+					t.Errorf("readme wrote %q, want %q", got, tc.wantReadme)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckAll(t *testing.T) {
+	cases := []struct {
+		name       string
+		files      map[string]string
+		readme     bool // runs readme before check-all
+		wantErr    string
+		wantStdout []string
+	}{
+		{
+			name:       "passing",
+			readme:     true,
+			wantStdout: []string{"check-all passed for workflow.config.json"},
+		},
+		{
+			name: "orphan_and_unformatted",
+			files: map[string]string{
+				"workflow.config.json":                     strings.Join(strings.Fields(testConfig), ""),
+				"workflows/deploy-cloudrun/deploy-old.yml": testWorkflowContents,
+			},
+			readme:  true,
+			wantErr: "check-all failed: found 2 problem(s) in workflow.config.json",
+			wantStdout: []string{
+				"1. orphaned file workflows/deploy-cloudrun/deploy-old.yml is not referenced by any workflow in workflow.config.json",
+				"2. workflow.config.json is not formatted, run 'go run scripts/generate.go fmt-config' to format it",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+			if tc.readme {
+				if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+					t.Fatalf("readme: %s", err)
+				}
+			}
+
+			stdout, _, err := runGenerate(t, dir, "check-all")
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("check-all: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("check-all error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantStdout) {
+				t.Errorf("check-all printed %q, want %q", got, tc.wantStdout)
+			}
+		})
+	}
+}
+
+func TestValidatePropertiesFileName(t *testing.T) {
+	files := map[string]string{
+		"workflow.config.json":                  strings.Replace(testConfig, "properties/deploy-app.properties.json", "properties/deploy.properties.json", 1),
+		"properties/deploy-app.properties.json": "",
+		"properties/deploy.properties.json":     testProperties(`["Cloud Run", "Deployment"]`),
+	}
+
+	cases := []struct {
+		name           string
+		args           []string
+		wantErr        string
+		wantStdout     []string
+		wantProperties string // the properties path in the config and on disk after the run
+	}{
+		{
+			name:           "mismatch",
+			args:           []string{"validate"},
+			wantErr:        "found 1 problem(s) in workflow.config.json",
+			wantStdout:     []string{"1. workflow deploy-app: properties file name does not match the workflow ID, expected deploy-app.properties.json, got deploy.properties.json"},
+			wantProperties: "properties/deploy.properties.json",
+		},
+		{
+			name:           "fix",
+			args:           []string{"validate", "--fix"},
+			wantStdout:     []string{},
+			wantProperties: "properties/deploy-app.properties.json",
+		},
+		{
+			name:    "fix_dry_run",
+			args:    []string{"validate", "--fix", "--dry-run"},
+			wantErr: "found 1 problem(s) in workflow.config.json",
+			wantStdout: []string{
+				"would rename: properties/deploy.properties.json -> properties/deploy-app.properties.json",
+				"would update: workflow.config.json",
+				"1. workflow deploy-app: properties file name does not match the workflow ID, expected deploy-app.properties.json, got deploy.properties.json",
+			},
+			wantProperties: "properties/deploy.properties.json",
+		},
+		{
+			name: "fix_diff",
+			args: []string{"validate", "--fix", "--diff"},
+			wantStdout: []string{
+				"--- workflow.config.json",
+				"+++ workflow.config.json",
+				`-     "propertiesPath": "properties/deploy.properties.json"`,
+				`+     "propertiesPath": "properties/deploy-app.properties.json"`,
+			},
+			wantProperties: "properties/deploy-app.properties.json",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, files)
+
+			stdout, _, err := runGenerate(t, dir, tc.args...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("validate error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantStdout) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantStdout)
+			}
+
+			if config := readTestFile(t, filepath.Join(dir, "workflow.config.json")); !strings.Contains(config, `"propertiesPath": "`+tc.wantProperties+`"`) {
+				t.Errorf("validate left config\n%s\nwant propertiesPath %s", config, tc.wantProperties)
+			}
+			if _, err := os.Stat(filepath.Join(dir, tc.wantProperties)); err != nil {
+				t.Errorf("validate left no properties file %s: %s", tc.wantProperties, err)
+			}
+		})
+	}
+}
+
+func TestCodeowners(t *testing.T) {
+	withCreators := func(appCreator string, otherCreator string) map[string]string {
+		files := map[string]string{
+			"properties/deploy-app.properties.json": strings.Replace(testProperties(`["Cloud Run", "Deployment"]`), "Google Cloud", appCreator, 1),
+		}
+		for name, contents := range testSecondWorkflowFiles {
+			files[name] = contents
+		}
+		files["properties/deploy-other.properties.json"] = strings.Replace(files["properties/deploy-other.properties.json"], "Google Cloud", otherCreator, 1)
+		return files
+	}
+
+	cases := []struct {
+		name       string
+		files      map[string]string
+		wantStdout []string
+		wantLogs   []string
+	}{
+		{
+			name:       "two_creators",
+			files:      withCreators("octocat", "@google-github-actions/maintainers"),
+			wantStdout: []string{"/workflows/deploy-cloudrun/ @google-github-actions/maintainers @octocat"},
+			wantLogs:   []string{},
+		},
+		{
+			name:       "same_creator",
+			files:      withCreators("@octocat", "octocat"),
+			wantStdout: []string{"/workflows/deploy-cloudrun/ @octocat"},
+			wantLogs:   []string{},
+		},
+		{
+			name:       "email_and_name",
+			files:      withCreators("octocat@example.com", "Google Cloud"),
+			wantStdout: []string{"/workflows/deploy-cloudrun/ octocat@example.com"},
+			wantLogs:   []string{`warning: creator "Google Cloud" of workflows/deploy-cloudrun is not a GitHub user, team or email address`},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, tc.files)
+
+			stdout, logs, err := runGenerate(t, dir, "codeowners")
+			if err != nil {
+				t.Fatalf("codeowners: %s", err)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantStdout) {
+				t.Errorf("codeowners printed %q, want %q", got, tc.wantStdout)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("codeowners logged %q, want %q", got, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestReadmeIncremental(t *testing.T) {
+	dir := newTestRepo(t, nil)
+	args := []string{"readme", "--incremental", "--regenerate-action-readmes"}
+
+	_, logs, err := runGenerate(t, dir, args...)
+	if err != nil {
+		t.Fatalf("first readme: %s", err)
+	}
+	want := []string{
+		"updated workflows/deploy-cloudrun/README.md",
+		"updated 1 action README(s), skipped 0 unchanged",
+		"updated README.md",
+	}
+	if got := logLines(logs); !reflect.DeepEqual(got, want) {
+		t.Errorf("first readme logged %q, want %q", got, want)
+	}
+
+	before := snapshotFiles(t, dir)
+
+	_, logs, err = runGenerate(t, dir, args...)
+	if err != nil {
+		t.Fatalf("second readme: %s", err)
+	}
+	want = []string{
+		"updated 0 action README(s), skipped 1 unchanged",
+		"skipped README.md, content is unchanged",
+	}
+	if got := logLines(logs); !reflect.DeepEqual(got, want) {
+		t.Errorf("second readme logged %q, want %q", got, want)
+	}
+	if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+		t.Errorf("second readme changed the files:\n%q\nwant:\n%q", after, before)
+	}
+}
+
+func TestValidateCategoryOrder(t *testing.T) {
+	unsorted := testProperties(`["Deployment", "Cloud Run"]`)
+	sorted := testProperties(`["Cloud Run", "Deployment"]`)
+
+	cases := []struct {
+		name           string
+		categories     string
+		args           []string
+		wantErr        string
+		wantStdout     []string
+		wantLogs       []string
+		wantProperties string
+	}{
+		{
+			name:           "empty",
+			categories:     testProperties(`[]`),
+			args:           []string{"validate"},
+			wantErr:        "found 1 problem(s) in workflow.config.json",
+			wantStdout:     []string{"1. workflow deploy-app: properties categories must not be empty"},
+			wantLogs:       []string{},
+			wantProperties: testProperties(`[]`),
+		},
+		{
+			name:           "unsorted",
+			categories:     unsorted,
+			args:           []string{"validate"},
+			wantStdout:     []string{},
+			wantLogs:       []string{`warning: workflow deploy-app: properties categories are not sorted, expected Cloud Run, Deployment`},
+			wantProperties: unsorted,
+		},
+		{
+			name:           "unsorted_strict",
+			categories:     unsorted,
+			args:           []string{"validate", "--strict"},
+			wantErr:        "found 1 problem(s) in workflow.config.json",
+			wantStdout:     []string{`1. workflow deploy-app: properties categories are not sorted, expected Cloud Run, Deployment`},
+			wantLogs:       []string{},
+			wantProperties: unsorted,
+		},
+		{
+			name:           "fix",
+			categories:     unsorted,
+			args:           []string{"validate", "--fix", "--strict"},
+			wantStdout:     []string{},
+			wantLogs:       []string{"sorted categories of properties/deploy-app.properties.json"},
+			wantProperties: sorted,
+		},
+		{
+			name:           "fix_dry_run",
+			categories:     unsorted,
+			args:           []string{"validate", "--fix", "--dry-run"},
+			wantStdout:     []string{"would update: properties/deploy-app.properties.json"},
+			wantLogs:       []string{`warning: workflow deploy-app: properties categories are not sorted, expected Cloud Run, Deployment`},
+			wantProperties: unsorted,
+		},
+		{
+			name:       "fix_diff",
+			categories: unsorted,
+			args:       []string{"validate", "--fix", "--diff"},
+			wantStdout: []string{
+				"--- properties/deploy-app.properties.json",
+				"+++ properties/deploy-app.properties.json",
+				"- " + strings.TrimSuffix(unsorted, "\n"),
+				"+ " + strings.TrimSuffix(sorted, "\n"),
+			},
+			wantLogs:       []string{"sorted categories of properties/deploy-app.properties.json"},
+			wantProperties: sorted,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, map[string]string{"properties/deploy-app.properties.json": tc.categories})
+
+			stdout, logs, err := runGenerate(t, dir, tc.args...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("validate: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("validate error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantStdout) {
+				t.Errorf("validate printed %q, want %q", got, tc.wantStdout)
+			}
+			if got := logLines(logs); !reflect.DeepEqual(got, tc.wantLogs) {
+				t.Errorf("validate logged %q, want %q", got, tc.wantLogs)
+			}
+			if got := readTestFile(t, filepath.Join(dir, "properties/deploy-app.properties.json")); got != tc.wantProperties {
+				t.Errorf("validate left properties %q, want %q", got, tc.wantProperties)
+			}
+		})
+	}
+}
+
+func TestMoveAction(t *testing.T) {
+	movedConfig := `{
+  "deploy-app": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-run/deploy-app.yml",
+    "propertiesPath": "properties/deploy-app.properties.json"
+  },
+  "deploy-other": {
+    "starter": false,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-run/deploy-other.yml",
+    "propertiesPath": "properties/deploy-other.properties.json"
+  }
+}
+`
+
+	t.Run("two_workflows", func(t *testing.T) {
+		dir := newTestRepo(t, testSecondWorkflowFiles)
+
+		_, logs, err := runGenerate(t, dir, "move-action", "deploy-cloudrun", "deploy-run")
+		if err != nil {
+			t.Fatalf("move-action: %s", err)
+		}
+
+		if got := readTestFile(t, filepath.Join(dir, "workflow.config.json")); got != movedConfig {
+			t.Errorf("move-action wrote config:\n%s\nwant:\n%s", got, movedConfig)
+		}
+		for _, name := range []string{"deploy-app.yml", "deploy-other.yml"} {
+			if _, err := os.Stat(filepath.Join(dir, "workflows", "deploy-run", name)); err != nil {
+				t.Errorf("move-action did not move %s: %s", name, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, "workflows", "deploy-cloudrun")); !os.IsNotExist(err) {
+			t.Errorf("move-action left workflows/deploy-cloudrun behind: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "properties", "deploy-other.properties.json")); err != nil {
+			t.Errorf("move-action moved the properties files: %s", err)
+		}
+
+		wantReadme := `# deploy-run examples
+
+` + "`Cloud Run` `Deployment`" + `
+
+| Name | Starter | Description |
+| ---- | ------- | ----------- |
+| [Deploy App](deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |
+| [Deploy Other](deploy-other.yml) |  | Deploy another app to Cloud Run. |
+`
+		if got := readTestFile(t, filepath.Join(dir, "workflows", "deploy-run", "README.md")); got != wantReadme {
+			t.Errorf("move-action rendered README:\n%s\nwant:\n%s", got, wantReadme)
+		}
+
+		want := []string{"moved workflows/deploy-cloudrun -> workflows/deploy-run and updated 2 workflow(s) in workflow.config.json"}
+		if got := logLines(logs); !reflect.DeepEqual(got, want) {
+			t.Errorf("move-action logged %q, want %q", got, want)
+		}
+	})
+
+	t.Run("dry_run", func(t *testing.T) {
+		dir := newTestRepo(t, testSecondWorkflowFiles)
+		before := snapshotFiles(t, dir)
+
+		stdout, _, err := runGenerate(t, dir, "move-action", "--dry-run", "deploy-cloudrun", "deploy-run")
+		if err != nil {
+			t.Fatalf("move-action: %s", err)
+		}
+
+		want := []string{
+			"would move: workflows/deploy-cloudrun -> workflows/deploy-run",
+			"would update: workflow.config.json",
+			"would update: workflows/deploy-run/README.md",
+		}
+		if got := logLines(stdout); !reflect.DeepEqual(got, want) {
+			t.Errorf("move-action printed %q, want %q", got, want)
+		}
+		if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+			t.Errorf("move-action --dry-run changed the files:\n%q\nwant:\n%q", after, before)
+		}
+	})
+
+	t.Run("config_write_failure", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write read-only files")
+		}
+
+		dir := newTestRepo(t, testSecondWorkflowFiles)
+		configPath := filepath.Join(dir, "workflow.config.json")
+		if err := os.Chmod(configPath, 0o444); err != nil {
+			t.Fatal(err)
+		}
+		before := snapshotFiles(t, dir)
+
+		if _, _, err := runGenerate(t, dir, "move-action", "deploy-cloudrun", "deploy-run"); err == nil || !strings.HasPrefix(err.Error(), "failed to write update workflow config: ") {
+			t.Fatalf("move-action error = %v, want a failure to write the config", err)
+		}
+		if after := snapshotFiles(t, dir); !reflect.DeepEqual(after, before) {
+			t.Errorf("move-action did not roll back the move:\n%q\nwant:\n%q", after, before)
+		}
+	})
+}
+
+func TestReadmeLinkBaseURL(t *testing.T) {
+	cases := []struct {
+		name        string
+		linkBaseURL string
+		wantLink    string
+	}{
+		{
+			name:     "unset",
+			wantLink: "|[deploy-app](workflows/deploy-cloudrun/deploy-app.yml) |",
+		},
+		{
+			name:        "trailing_slash",
+			linkBaseURL: "https://github.com/org/repo/blob/main/",
+			wantLink:    "|[deploy-app](https://github.com/org/repo/blob/main/workflows/deploy-cloudrun/deploy-app.yml) |",
+		},
+		{
+			name:        "no_trailing_slash",
+			linkBaseURL: "https://github.com/org/repo/blob/main",
+			wantLink:    "|[deploy-app](https://github.com/org/repo/blob/main/workflows/deploy-cloudrun/deploy-app.yml) |",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("LINK_BASE_URL", tc.linkBaseURL)
+			dir := newTestRepo(t, nil)
+
+			if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			readme := readTestFile(t, filepath.Join(dir, "README.md"))
+			if !strings.Contains(readme, tc.wantLink) {
+				t.Errorf("readme wrote:\n%s\nwant a line with %s", readme, tc.wantLink)
+			}
+			// the action README link stays relative to the README
+			if !strings.Contains(readme, "### [deploy-cloudrun](workflows/deploy-cloudrun/README.md)") {
+				t.Errorf("readme wrote:\n%s\nwant a relative action README link", readme)
+			}
+		})
+	}
+}
+
+func TestReadmePreview(t *testing.T) {
+	licenseHeader := `# Copyright 2024 Google LLC
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+
+`
+
+	cases := []struct {
+		name         string
+		previewLines string
+		wantPreview  string
+		wantErr      string
+	}{
+		{
+			name:         "off",
+			previewLines: "0",
+		},
+		{
+			name:         "three_lines",
+			previewLines: "3",
+			wantPreview:  "<pre><code>name: Deploy App\n\non:</code></pre>",
+		},
+		{
+			name:         "invalid",
+			previewLines: "three",
+			wantErr:      "invalid EMBED_PREVIEW_LINES three, should be 0 or a positive number of lines",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("EMBED_PREVIEW_LINES", tc.previewLines)
+			dir := newTestRepo(t, map[string]string{
+				"workflows/deploy-cloudrun/deploy-app.yml": licenseHeader + testWorkflowContents,
+			})
+
+			_, _, err := runGenerate(t, dir, "readme")
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("readme error = %v, want %s", err, tc.wantErr)
+				}
+
+				// the commands not rendering READMEs ignore the previews
+				if _, _, err := runGenerate(t, dir, "list"); err != nil {
+					t.Errorf("list: %s", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			readme := readTestFile(t, filepath.Join(dir, "README.md"))
+			if tc.wantPreview == "" && strings.Contains(readme, "<pre><code>") {
+				t.Errorf("readme wrote:\n%s\nwant no preview", readme)
+			}
+			if tc.wantPreview != "" && !strings.Contains(readme, tc.wantPreview) {
+				t.Errorf("readme wrote:\n%s\nwant the preview %q", readme, tc.wantPreview)
+			}
+			if strings.Contains(readme, "Copyright") || strings.Contains(readme, "Licensed under") {
+				t.Errorf("readme wrote:\n%s\nwant no license header", readme)
+			}
+		})
+	}
+}
+
+func TestValidateDuplicateWorkflows(t *testing.T) {
+	files := map[string]string{
+		"workflow.config.json": `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-copy": {"starter": false, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-copy.yml", "propertiesPath": "properties/deploy-copy.properties.json"},
+  "deploy-other": {"starter": false, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-other.yml", "propertiesPath": "properties/deploy-other.properties.json"}
+}
+`,
+		// the same workflow with a license header and different whitespace
+		"workflows/deploy-cloudrun/deploy-copy.yml":  "# Copyright 2024 Google LLC\n\n" + strings.ReplaceAll(testWorkflowContents, "\n\n", "\n\n\n"),
+		"properties/deploy-copy.properties.json":     strings.Replace(testProperties(`["Cloud Run", "Deployment"]`), "Deploy App", "Deploy Copy", 1),
+		"workflows/deploy-cloudrun/deploy-other.yml": testSecondWorkflowFiles["workflows/deploy-cloudrun/deploy-other.yml"],
+		"properties/deploy-other.properties.json":    testSecondWorkflowFiles["properties/deploy-other.properties.json"],
+	}
+	dir := newTestRepo(t, files)
+
+	stdout, logs, err := runGenerate(t, dir, "validate")
+	if err != nil {
+		t.Fatalf("validate: %s\n%s", err, stdout)
+	}
+
+	want := []string{"warning: workflows deploy-app, deploy-copy have identical content, keep one of them or make them differ"}
+	if got := logLines(logs); !reflect.DeepEqual(got, want) {
+		t.Errorf("validate logged %q, want %q", got, want)
+	}
+}
+
+func TestWorkflowPropertiesTitle(t *testing.T) {
+	cases := []struct {
+		name           string
+		args           []string
+		propertiesPath string
+		wantName       string
+		wantType       string // in the scaffolded description
+	}{
+		{
+			name:           "json",
+			args:           []string{"workflow", "deploy-cloudrun/cloudrun-source-deploy"},
+			propertiesPath: "properties/cloudrun-source-deploy.properties.json",
+			wantName:       "Cloud Run Source Deploy",
+			wantType:       "deployments",
+		},
+		{
+			name:           "yaml",
+			args:           []string{"workflow", "--format", "yaml", "--type", "ci", "deploy-cloudrun/gke_build"},
+			propertiesPath: "properties/gke_build.properties.yaml",
+			wantName:       "GKE Build",
+			wantType:       "ci",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+
+			if _, _, err := runGenerate(t, dir, tc.args...); err != nil {
+				t.Fatalf("workflow: %s", err)
+			}
+
+			var properties examples.PropertiesConfig
+			if err := examples.LoadConfigFile(&properties, filepath.Join(dir, tc.propertiesPath)); err != nil {
+				t.Fatal(err)
+			}
+			if properties.Name != tc.wantName {
+				t.Errorf("workflow scaffolded name %q, want %q", properties.Name, tc.wantName)
+			}
+			workflowID := path.Base(tc.args[len(tc.args)-1])
+			if want := fmt.Sprintf("%s - A new %s workflow template.", workflowID, tc.wantType); properties.Description != want {
+				t.Errorf("workflow scaffolded description %q, want %q", properties.Description, want)
+			}
+		})
+	}
+}
+
+func TestReadmeConfigContents(t *testing.T) {
+	cases := []struct {
+		name       string
+		config     string
+		args       []string
+		wantErr    string
+		wantReadme string // a line of the README
+	}{
+		{
+			name:    "empty_file",
+			config:  "\n",
+			args:    []string{"readme"},
+			wantErr: "failed to load workflow config workflow.config.json: config is empty, it should be a JSON object of workflows such as {}",
+		},
+		{
+			name:       "empty_object",
+			config:     "{}\n",
+			args:       []string{"readme"},
+			wantReadme: "No workflows have been added yet, see [CONTRIBUTING.md](CONTRIBUTING.md) to add one.",
+		},
+		{
+			name:       "empty_object_split",
+			config:     "{}\n",
+			args:       []string{"readme", "--split"},
+			wantReadme: "No workflows have been added yet, see [CONTRIBUTING.md](CONTRIBUTING.md) to add one.",
+		},
+		{
+			name:       "single_workflow",
+			config:     testConfig,
+			args:       []string{"readme"},
+			wantReadme: "|[deploy-app](workflows/deploy-cloudrun/deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"workflow.config.json": tc.config}
+			if tc.config != testConfig {
+				// an empty config leaves the files of testRepoFiles orphaned
+				for _, name := range []string{"workflows/deploy-cloudrun/README.md", "workflows/deploy-cloudrun/deploy-app.yml", "properties/deploy-app.properties.json"} {
+					files[name] = ""
+				}
+			}
+			dir := newTestRepo(t, files)
+
+			_, _, err := runGenerate(t, dir, tc.args...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("readme error = %v, want %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			if readme := readTestFile(t, filepath.Join(dir, "README.md")); !strings.Contains(readme, tc.wantReadme+"\n") {
+				t.Errorf("readme wrote:\n%s\nwant the line %s", readme, tc.wantReadme)
+			}
+		})
+	}
+}
+
+func TestValidatePropertiesCommand(t *testing.T) {
+	files := map[string]string{
+		"properties/no-description.properties.json": `{"name": "No Description", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run"]}` + "\n",
+		// a broken workflow that validate-properties does not check
+		"workflows/deploy-cloudrun/deploy-app.yml": "on: push\n",
+	}
+
+	cases := []struct {
+		name       string
+		args       []string
+		wantErr    string
+		wantStdout []string
+	}{
+		{
+			name:       "valid",
+			args:       []string{"validate-properties", "properties/deploy-app.properties.json"},
+			wantStdout: []string{},
+		},
+		{
+			name:       "missing_description",
+			args:       []string{"validate-properties", "properties/deploy-app.properties.json", "properties/no-description.properties.json"},
+			wantErr:    "found 1 problem(s) in 2 properties file(s)",
+			wantStdout: []string{"1. properties/no-description.properties.json: properties description must not be empty"},
+		},
+		{
+			name:       "config",
+			args:       []string{"validate-properties"},
+			wantStdout: []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, files)
+
+			stdout, _, err := runGenerate(t, dir, tc.args...)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("validate-properties: %s", err)
+			}
+			if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Fatalf("validate-properties error = %v, want %s", err, tc.wantErr)
+			}
+			if got := logLines(stdout); !reflect.DeepEqual(got, tc.wantStdout) {
+				t.Errorf("validate-properties printed %q, want %q", got, tc.wantStdout)
+			}
+		})
+	}
+}
+
+func TestJSONLD(t *testing.T) {
+	cases := []struct {
+		name        string
+		linkBaseURL string
+		wantErr     string
+	}{
+		{
+			name:        "absolute",
+			linkBaseURL: "https://github.com/org/repo/blob/main/",
+		},
+		{
+			name:    "unset",
+			wantErr: "json-ld requires LINK_BASE_URL",
+		},
+		{
+			name:        "relative",
+			linkBaseURL: "docs/",
+			wantErr:     `json-ld requires an absolute LINK_BASE_URL, got "docs/"`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("LINK_BASE_URL", tc.linkBaseURL)
+			dir := newTestRepo(t, nil)
+
+			stdout, _, err := runGenerate(t, dir, "json-ld")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("json-ld returned %v, want an error containing %q", err, tc.wantErr)
+				}
+				if stdout != "" {
+					t.Errorf("json-ld printed %q, want nothing", stdout)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("json-ld: %s", err)
+			}
+
+			var got []map[string]interface{}
+			if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+				t.Fatalf("json-ld printed invalid JSON %q: %s", stdout, err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("json-ld printed %d objects, want 1", len(got))
+			}
+			if got[0]["@type"] != "SoftwareSourceCode" {
+				t.Errorf("json-ld printed @type %v, want SoftwareSourceCode", got[0]["@type"])
+			}
+			if want := "https://github.com/org/repo/blob/main/workflows/deploy-cloudrun/deploy-app.yml"; got[0]["url"] != want {
+				t.Errorf("json-ld printed url %v, want %s", got[0]["url"], want)
+			}
+		})
+	}
+}
+
+func TestReadmeFeatured(t *testing.T) {
+	featuredProperties := `{"name": "Deploy App", "description": "Deploy an app to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run", "Deployment"], "featured": true}` + "\n"
+
+	cases := []struct {
+		name         string
+		properties   string
+		wantFeatured string // the featured section, none when empty
+	}{
+		{
+			name:       "featured",
+			properties: featuredProperties,
+			wantFeatured: `## Featured Examples
+
+| Name                                                         | Action                    | Description      |
+| ------------------------------------------------------------ | ------------------------- | ---------------- |
+|[Deploy App](workflows/deploy-cloudrun/deploy-app.yml) | [deploy-cloudrun](#deploy-cloudrun) | Deploy an app to Cloud Run. |
+
+`,
+		},
+		{
+			name:       "not_featured",
+			properties: testProperties(`["Cloud Run", "Deployment"]`),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"properties/deploy-app.properties.json": tc.properties}
+			for name, contents := range testSecondWorkflowFiles {
+				files[name] = contents
+			}
+			dir := newTestRepo(t, files)
+
+			if _, _, err := runGenerate(t, dir, "readme"); err != nil {
+				t.Fatalf("readme: %s", err)
+			}
+
+			readme := readTestFile(t, filepath.Join(dir, "README.md"))
+			if tc.wantFeatured == "" {
+				if strings.Contains(readme, "Featured Examples") {
+					t.Errorf("readme wrote:\n%s\nwant no featured section", readme)
+				}
+			} else if !strings.Contains(readme, tc.wantFeatured) {
+				t.Errorf("readme wrote:\n%s\nwant the featured section:\n%s", readme, tc.wantFeatured)
+			}
+
+			// featured or not, every workflow is listed in its action
+			for _, row := range []string{
+				"|[deploy-app](workflows/deploy-cloudrun/deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |",
+				"|[deploy-other](workflows/deploy-cloudrun/deploy-other.yml) |  | Deploy another app to Cloud Run. |",
+			} {
+				if !strings.Contains(readme, row) {
+					t.Errorf("readme wrote:\n%s\nwant the action row %s", readme, row)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		workflow string
+		want     []jsonProblem
+	}{
+		{
+			name:     "yaml_parse_error",
+			workflow: strings.Replace(testWorkflowContents, "    runs-on: ubuntu-latest", "    runs-on: ubuntu: latest", 1),
+			want: []jsonProblem{{
+				WorkflowID: "deploy-app",
+				Path:       "workflows/deploy-cloudrun/deploy-app.yml",
+				Line:       13,
+				Severity:   "error",
+				Message:    "invalid YAML in workflows/deploy-cloudrun/deploy-app.yml: yaml: line 13: mapping values are not allowed in this context",
+			}},
+		},
+		{
+			name:     "tab_indented",
+			workflow: strings.Replace(testWorkflowContents, "  contents: read", "\tcontents: read", 1),
+			want: []jsonProblem{{
+				WorkflowID: "deploy-app",
+				Path:       "workflows/deploy-cloudrun/deploy-app.yml",
+				Line:       9,
+				Severity:   "error",
+				Message:    "invalid YAML in workflows/deploy-cloudrun/deploy-app.yml: line 9 is indented with a tab, use spaces",
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, map[string]string{"workflows/deploy-cloudrun/deploy-app.yml": tc.workflow})
+
+			stdout, _, err := runGenerate(t, dir, "validate", "--json")
+			if err == nil || err.Error() != "found 1 problem(s) in workflow.config.json" {
+				t.Errorf("validate --json error = %v, want found 1 problem(s) in workflow.config.json", err)
+			}
+
+			var got []jsonProblem
+			if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+				t.Fatalf("validate --json printed invalid JSON %q: %s", stdout, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("validate --json printed %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// testManyWorkflowFiles returns the files of three actions with three workflows each, replacing the
+// workflow of testRepoFiles. Each workflow has a warning, so the order of the logs and problems shows.
+func testManyWorkflowFiles(t *testing.T) map[string]string {
+	t.Helper()
+
+	config := map[string]examples.Workflow{}
+	files := map[string]string{}
+	for _, action := range []string{"deploy-cloudrun", "auth", "get-gke-credentials"} {
+		files["workflows/"+action+"/README.md"] = "# " + action + "\n"
+		for _, name := range []string{"alpha", "beta", "gamma"} {
+			workflowID := action + "-" + name
+			config[workflowID] = examples.Workflow{
+				Type:           "deployments",
+				WorkflowPath:   "workflows/" + action + "/" + workflowID + ".yml",
+				PropertiesPath: "properties/" + workflowID + ".properties.json",
+			}
+			files[config[workflowID].WorkflowPath] = strings.Replace(testWorkflowContents, "Deploy App", "Workflow "+workflowID, 1)
+			// the description without a trailing period is a warning
+			files[config[workflowID].PropertiesPath] = `{"name": "Workflow ` + workflowID + `", "description": "Workflow ` + workflowID + `", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Deployment"]}` + "\n"
+		}
+	}
+	configBytes, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["workflow.config.json"] = string(configBytes) + "\n"
+	files["workflows/deploy-cloudrun/deploy-app.yml"] = ""
+	files["properties/deploy-app.properties.json"] = ""
+
+	return files
+}
+
+func TestRepeatedRunsIdentical(t *testing.T) {
+	files := testManyWorkflowFiles(t)
+
+	for _, args := range [][]string{
+		{"validate", "--verbose"},
+		{"readme", "--verbose"},
+		{"doctor"},
+	} {
+		args := args
+
+		t.Run(args[0], func(t *testing.T) {
+			var wantStdout, wantLogs string
+			for i := 0; i < 20; i++ {
+				dir := newTestRepo(t, files)
+
+				stdout, logs, _ := runGenerate(t, dir, args...)
+				if i == 0 {
+					wantStdout, wantLogs = stdout, logs
+					continue
+				}
+				if stdout != wantStdout {
+					t.Fatalf("run %d printed:\n%s\nwant the output of the first run:\n%s", i, stdout, wantStdout)
+				}
+				if logs != wantLogs {
+					t.Fatalf("run %d logged:\n%s\nwant the logs of the first run:\n%s", i, logs, wantLogs)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkers(t *testing.T) {
+	files := testManyWorkflowFiles(t)
+
+	type result struct {
+		stdout, logs string
+		files        map[string]string
+	}
+	run := func(workers string) result {
+		t.Helper()
+
+		dir := newTestRepo(t, files)
+		stdout, logs, err := runGenerate(t, dir, "readme", "--regenerate-action-readmes", "--verbose", "--workers", workers)
+		if err != nil {
+			t.Fatalf("readme --workers %s: %s", workers, err)
+		}
+		return result{stdout: stdout, logs: logs, files: snapshotFiles(t, dir)}
+	}
+
+	// one worker loads and renders in order, the output of more workers must not differ
+	want := run("1")
+	got := run("8")
+	if got.stdout != want.stdout {
+		t.Errorf("readme --workers 8 printed:\n%s\nwant the output of --workers 1:\n%s", got.stdout, want.stdout)
+	}
+	if got.logs != want.logs {
+		t.Errorf("readme --workers 8 logged:\n%s\nwant the logs of --workers 1:\n%s", got.logs, want.logs)
+	}
+	if !reflect.DeepEqual(got.files, want.files) {
+		t.Errorf("readme --workers 8 wrote:\n%v\nwant the files of --workers 1:\n%v", got.files, want.files)
+	}
+
+	_, _, err := runGenerate(t, newTestRepo(t, nil), "readme", "--workers", "0")
+	if want := "--workers must be at least 1, got 0"; err == nil || err.Error() != want {
+		t.Errorf("readme --workers 0 error = %v, want %s", err, want)
+	}
+
+	// the commands without a worker pool ignore --workers
+	if _, _, err := runGenerate(t, newTestRepo(t, nil), "list", "--workers", "0"); err != nil {
+		t.Errorf("list --workers 0: %s", err)
+	}
+}
+
+func TestNewAction(t *testing.T) {
+	cases := []struct {
+		name       string
+		args       []string
+		wantErr    string
+		wantFiles  []string
+		wantConfig string
+	}{
+		{
+			name:       "action",
+			args:       []string{"deploy-appengine"},
+			wantFiles:  []string{"workflows/deploy-appengine/README.md"},
+			wantConfig: testConfig,
+		},
+		{
+			name: "first_workflow",
+			args: []string{"--starter", "deploy-appengine", "appengine-deploy"},
+			wantFiles: []string{
+				"workflows/deploy-appengine/README.md",
+				"workflows/deploy-appengine/appengine-deploy.yml",
+				"properties/appengine-deploy.properties.json",
+			},
+			wantConfig: `{
+  "appengine-deploy": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-appengine/appengine-deploy.yml",
+    "propertiesPath": "properties/appengine-deploy.properties.json"
+  },
+  "deploy-app": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml",
+    "propertiesPath": "properties/deploy-app.properties.json"
+  }
+}
+`,
+		},
+		{
+			name:       "exists",
+			args:       []string{"deploy-cloudrun"},
+			wantErr:    "action deploy-cloudrun already exists: workflows/deploy-cloudrun",
+			wantConfig: testConfig,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			before := snapshotFiles(t, dir)
+
+			_, _, err := runGenerate(t, dir, append([]string{"new-action"}, tc.args...)...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("new-action error = %v, want %s", err, tc.wantErr)
+				}
+				if got := snapshotFiles(t, dir); !reflect.DeepEqual(got, before) {
+					t.Errorf("new-action changed the files to %v, want them unchanged", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("new-action: %s", err)
+			}
+
+			for _, name := range tc.wantFiles {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("new-action did not create %s: %s", name, err)
+				}
+			}
+			if readme := readTestFile(t, filepath.Join(dir, "workflows/deploy-appengine/README.md")); !strings.HasPrefix(readme, "# deploy-appengine examples\n") {
+				t.Errorf("new-action wrote the action README:\n%s\nwant the deploy-appengine heading", readme)
+			}
+			if got := readTestFile(t, filepath.Join(dir, "workflow.config.json")); got != tc.wantConfig {
+				t.Errorf("new-action wrote config:\n%s\nwant:\n%s", got, tc.wantConfig)
+			}
+		})
+	}
+}
+
+func TestReadmeSplit(t *testing.T) {
+	otherProperties := `{"name": "Deploy Other", "description": "Deploy another app to Cloud Run.", "creator": "Google Cloud", "iconName": "google-cloud", "categories": ["Cloud Run", "Deployment"], ` +
+		`"aliases": ["Other"], "requiredSecrets": ["WIF_PROVIDER"], "requiredPermissions": ["id-token: write"], "variables": [{"name": "PROJECT_ID", "description": "Google Cloud project ID", "required": true}]}` + "\n"
+	otherWorkflow := strings.Replace(testWorkflowContents, "Deploy App", "Deploy Other", 1) + `      - run: echo "${{ secrets.WIF_PROVIDER }}" "${{ env.PROJECT_ID }}"
+`
+
+	cases := []struct {
+		name        string
+		linkBaseURL string
+		wantReadme  string
+	}{
+		{
+			name: "relative",
+			wantReadme: `# deploy-cloudrun examples
+
+` + "`Cloud Run` `Deployment`" + `
+
+| Name | Starter | Description |
+| ---- | ------- | ----------- |
+| [Deploy App](deploy-app.yml) | ✅ | Deploy an app to Cloud Run. |
+| [Deploy Other](deploy-other.yml) |  | Deploy another app to Cloud Run. Also known as: _Other_. Required secrets: ` + "`WIF_PROVIDER`" + `. Required permissions: ` + "`id-token: write`" + `. |
+
+**Deploy Other** variables:
+
+| Variable | Required | Description |
+| -------- | -------- | ----------- |
+| ` + "`PROJECT_ID`" + ` | ✅ | Google Cloud project ID |
+
+<details>
+<summary>Deploy App</summary>
+
+<pre><code>name: Deploy App</code></pre>
+
+</details>
+
+<details>
+<summary>Deploy Other</summary>
+
+<pre><code>name: Deploy Other</code></pre>
+
+</details>
+`,
+		},
+		{
+			name:        "link_base_url",
+			linkBaseURL: "https://github.com/org/repo/blob/main/",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("LINK_BASE_URL", tc.linkBaseURL)
+			t.Setenv("EMBED_PREVIEW_LINES", "1")
+			files := map[string]string{}
+			for name, contents := range testSecondWorkflowFiles {
+				files[name] = contents
+			}
+			files["properties/deploy-other.properties.json"] = otherProperties
+			files["workflows/deploy-cloudrun/deploy-other.yml"] = otherWorkflow
+			dir := newTestRepo(t, files)
+
+			if _, _, err := runGenerate(t, dir, "readme", "--split"); err != nil {
+				t.Fatalf("readme --split: %s", err)
+			}
+
+			// the index only links to the action README, which lists the workflows
+			readme := readTestFile(t, filepath.Join(dir, "README.md"))
+			if want := "- [deploy-cloudrun](workflows/deploy-cloudrun/README.md), 2 workflow(s)\n"; !strings.Contains(readme, want) {
+				t.Errorf("readme --split wrote:\n%s\nwant the action link %s", readme, want)
+			}
+			if strings.Contains(readme, "deploy-app.yml") {
+				t.Errorf("readme --split wrote:\n%s\nwant no workflow links", readme)
+			}
+
+			actionReadme := readTestFile(t, filepath.Join(dir, "workflows", "deploy-cloudrun", "README.md"))
+			if tc.wantReadme != "" && actionReadme != tc.wantReadme {
+				t.Errorf("readme --split wrote action README:\n%s\nwant:\n%s", actionReadme, tc.wantReadme)
+			}
+			if tc.linkBaseURL != "" {
+				for _, link := range []string{
+					"[Deploy App](https://github.com/org/repo/blob/main/workflows/deploy-cloudrun/deploy-app.yml)",
+					"[Deploy Other](https://github.com/org/repo/blob/main/workflows/deploy-cloudrun/deploy-other.yml)",
+				} {
+					if !strings.Contains(actionReadme, link) {
+						t.Errorf("readme --split wrote action README:\n%s\nwant the link %s", actionReadme, link)
+					}
+				}
+			}
+
+			// the action READMEs are up to date with the same rendering
+			if _, _, err := runGenerate(t, dir, "readme", "--split", "--check"); err != nil {
+				t.Errorf("readme --split --check: %s", err)
+			}
+		})
+	}
+}
@@ -12,36 +12,82 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+//go:build ignore
+
 package main
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/signal"
 	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 	"syscall"
+	"time"
+
+	"github.com/google-github-actions/example-workflows/pkg/examples"
+)
+
+const (
+	// retryAttempts is the number of times a file operation is attempted before giving up on transient errors
+	retryAttempts = 5
+)
+
+const (
+	changeAdded     = "added"
+	changeChanged   = "changed"
+	changeUnchanged = "unchanged"
 )
 
 var (
 	workflowConfigPath string = path.Clean(path.Join("workflow.config.json"))
 	outputPath         string = path.Clean(defaultEnv("OUTPUT_PATH", path.Join("..", "starter-workflows")))
 	outputPropsDirName string = "properties"
-	outputFilePrefix   string = "google"
+
+	// outputFilePrefix and workflowFilter are read from OUTPUT_FILE_PREFIX and WORKFLOW_FILTER by realMain
+	outputFilePrefix string
+	workflowFilter   string
+
+	// outputPtr overrides OUTPUT_PATH, so the output can be set without changing the environment
+	outputPtr = flag.String("output", outputPath, "path to the starter workflows repository, defaults to OUTPUT_PATH")
+
+	// workflowsDirPtr is the directory of the action folders, whose workflows WORKFLOW_FILTER matches by action-name/workflow-name
+	workflowsDirPtr = flag.String("workflows-dir", examples.DefaultWorkflowsDir, "directory of the action folders, the workflows in it are matched by WORKFLOW_FILTER as action-name/workflow-name")
+
+	// quietPtr suppresses the line printed and logged for each file, only the number of files is reported
+	quietPtr = flag.Bool("quiet", defaultEnv("QUIET", "false") == "true", "only report the number of planned and copied files instead of each file, defaults to QUIET")
+
+	// prunePtr removes the prefixed files of the type directories that are not copied from a current starter workflow
+	prunePtr = flag.Bool("prune", false, "after copying, remove the files of workflows no longer released from the starter workflows repository")
+	// yesPtr removes the files found by --prune without asking for confirmation
+	yesPtr = flag.Bool("yes", false, "remove the files found by --prune without asking for confirmation")
+
+	// retryDelay is the delay before the first retry of a file operation, doubled for each following retry
+	retryDelay = 100 * time.Millisecond
+
+	// linkFile hard links the copied files, replaced in tests to simulate links across filesystems
+	linkFile = os.Link
+
+	// typeDirs are the starter workflows repository directories for each valid workflow type,
+	// overridden with TYPE_DIRS, e.g. TYPE_DIRS=ci=ci-custom,deployments=deploy
+	typeDirs = defaultTypeDirs()
 )
 
-// Workflow is the object properties for each workflow
-type Workflow struct {
-	Starter        bool   `json:"starter"`
-	Type           string `json:"type"`
-	WorkflowPath   string `json:"workflowPath"`
-	PropertiesPath string `json:"propertiesPath"`
+// WorkflowProperties is the subset of the workflow properties file used by the release
+type WorkflowProperties struct {
+	Deprecated bool `json:"deprecated"`
 }
 
-// WorkflowConfig is the object referencing all workflow configs
-type WorkflowConfig map[string]Workflow
-
 // FileCopyConfig is the source and destination file path for the files to copy
 type FileCopyConfig struct {
 	Source string
@@ -52,41 +98,87 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	if err := realMain(ctx); err != nil {
+	flag.Parse()
+
+	logger := examples.NewLogger(os.Stderr, examples.LevelInfo)
+	if err := realMain(ctx, logger); err != nil {
 		cancel()
-		fmt.Fprintf(os.Stderr, "%s\n", err)
+		logger.Errorf("%s", err)
 		os.Exit(1)
 	}
 }
 
-func realMain(ctx context.Context) error {
-	configBytes, err := os.ReadFile(workflowConfigPath)
+func realMain(ctx context.Context, logger examples.Logger) error {
+	outputPath = path.Clean(*outputPtr)
+	outputFilePrefix = defaultEnv("OUTPUT_FILE_PREFIX", "google")
+	workflowFilter = defaultEnv("WORKFLOW_FILTER", "")
+	workflowConfigPath = examples.DetectWorkflowConfigPath(workflowConfigPath)
+
+	var workflowConfig examples.WorkflowConfig
+	if err := examples.LoadConfigFile(&workflowConfig, workflowConfigPath); err != nil {
+		return fmt.Errorf("failed to load config file: %w", err)
+	}
+
+	if err := parseTypeDirs(defaultEnv("TYPE_DIRS", "")); err != nil {
+		return fmt.Errorf("failed to parse TYPE_DIRS: %w", err)
+	}
+
+	filter, err := parseWorkflowFilter(workflowFilter, workflowConfig, path.Clean(*workflowsDirPtr))
 	if err != nil {
-		return fmt.Errorf("failed to read config file: %w", err)
+		return fmt.Errorf("failed to parse WORKFLOW_FILTER: %w", err)
 	}
 
-	var workflowConfig WorkflowConfig
-	if err := json.Unmarshal(configBytes, &workflowConfig); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	// the files of the workflows excluded by the filter would be pruned
+	if *prunePtr && filter != nil {
+		return fmt.Errorf("--prune cannot be used with WORKFLOW_FILTER")
 	}
 
 	isInvalid := false
 
 	filesToCopy := make([]FileCopyConfig, 0)
-	for workflowID, workflow := range workflowConfig {
+	destWorkflowIDs := map[string]string{}
+	for _, workflowID := range examples.SortedWorkflowIDs(workflowConfig) {
+		workflow := workflowConfig[workflowID]
+
 		// skip non-starter workflows
 		if !workflow.Starter {
 			continue
 		}
 
+		// skip workflows excluded by the filter
+		if filter != nil && !filter[workflowID] {
+			continue
+		}
+
+		typeDir, ok := resolveTypeDir(workflow.Type)
+		if !ok {
+			isInvalid = true
+			logger.Errorf("invalid type for workflow %s: type - %s", workflowID, workflow.Type)
+		}
+
 		if _, err := os.Stat(workflow.WorkflowPath); os.IsNotExist(err) {
 			isInvalid = true
-			fmt.Println(fmt.Sprintf("workflow file does not exist for workflow %s: path - %s", workflowID, workflow.WorkflowPath))
+			logger.Errorf("workflow file does not exist for workflow %s: path - %s", workflowID, workflow.WorkflowPath)
 		}
 
 		if _, err := os.Stat(workflow.PropertiesPath); os.IsNotExist(err) {
 			isInvalid = true
-			fmt.Println(fmt.Sprintf("properties file does not exist for workflow %s: path - %s", workflowID, workflow.PropertiesPath))
+			logger.Errorf("properties file does not exist for workflow %s: path - %s", workflowID, workflow.PropertiesPath)
+		}
+
+		// the starter workflows repository only accepts JSON properties files
+		if path.Ext(workflow.PropertiesPath) != ".json" {
+			isInvalid = true
+			logger.Errorf("properties file must be JSON for starter workflow %s: path - %s", workflowID, workflow.PropertiesPath)
+		} else if properties, err := loadProperties(workflow.PropertiesPath); err == nil {
+			// skip deprecated workflows, they are only kept in this repository
+			if properties.Deprecated {
+				logger.Infof("skipping deprecated starter workflow %s", workflowID)
+				continue
+			}
+		} else if !os.IsNotExist(err) {
+			isInvalid = true
+			logger.Errorf("failed to load properties file for workflow %s: %s", workflowID, err)
 		}
 
 		// add workflow yaml to copy list
@@ -94,7 +186,7 @@ func realMain(ctx context.Context) error {
 		workflowDestFilename := fmt.Sprintf("%s-%s", outputFilePrefix, workflowFilename)
 		filesToCopy = append(filesToCopy, FileCopyConfig{
 			Source: workflow.WorkflowPath,
-			Dest:   path.Join(outputPath, workflow.Type, workflowDestFilename),
+			Dest:   path.Join(outputPath, typeDir, workflowDestFilename),
 		})
 
 		// add properties file to copy list
@@ -102,8 +194,18 @@ func realMain(ctx context.Context) error {
 		propertiesDestFilename := fmt.Sprintf("%s-%s", outputFilePrefix, propertiesFilename)
 		filesToCopy = append(filesToCopy, FileCopyConfig{
 			Source: workflow.PropertiesPath,
-			Dest:   path.Join(outputPath, workflow.Type, outputPropsDirName, propertiesDestFilename),
+			Dest:   path.Join(outputPath, typeDir, outputPropsDirName, propertiesDestFilename),
 		})
+
+		// destinations only differing in case overwrite each other on case-insensitive filesystems
+		for _, file := range filesToCopy[len(filesToCopy)-2:] {
+			key := strings.ToLower(file.Dest)
+			if existingID, ok := destWorkflowIDs[key]; ok {
+				isInvalid = true
+				logger.Errorf("destination of workflow %s collides with workflow %s on case-insensitive filesystems: path - %s", workflowID, existingID, file.Dest)
+			}
+			destWorkflowIDs[key] = workflowID
+		}
 	}
 
 	// handle invalid config messaging and fail
@@ -111,19 +213,372 @@ func realMain(ctx context.Context) error {
 		return fmt.Errorf("failed to process invalid configs")
 	}
 
-	// copy all files to destination
-	for _, file := range filesToCopy {
+	changes, err := planChanges(filesToCopy)
+	if err != nil {
+		return fmt.Errorf("failed to compare files: %w", err)
+	}
+	printChangelog(changes, *quietPtr)
+
+	// copy added and changed files to destination, unchanged files are left as is
+	filesToWrite := append(changes[changeAdded], changes[changeChanged]...)
+	for _, file := range filesToWrite {
 		// remove any existing destination files
-		os.Remove(file.Dest)
-		if err := os.Link(file.Source, file.Dest); err != nil {
+		if err := retryTransient(logger, func() error { return os.Remove(file.Dest) }); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file.Dest, err)
+		}
+		// the type, nested type and properties directories may not exist yet in a fresh output tree
+		if err := examples.MkdirParent(file.Dest); err != nil {
+			return err
+		}
+		if err := linkOrCopyFile(file.Source, file.Dest, logger); err != nil {
 			return fmt.Errorf("failed to copy files: %w", err)
 		}
-		fmt.Println(fmt.Sprintf("successfully copied %s -> %s", file.Source, file.Dest))
+
+		checksum, err := verifyChecksum(file)
+		if err != nil {
+			return fmt.Errorf("failed to verify copied file: %w", err)
+		}
+		if !*quietPtr {
+			logger.Infof("successfully copied %s -> %s (sha256: %s)", file.Source, file.Dest, checksum)
+		}
+	}
+	logger.Infof("copied %d file(s) to %s, %d unchanged", len(filesToWrite), outputPath, len(changes[changeUnchanged]))
+
+	if *prunePtr {
+		if err := pruneStaleFiles(filesToCopy, logger); err != nil {
+			return fmt.Errorf("failed to prune stale files: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// pruneStaleFiles removes the stale files of the output after asking for confirmation, unless --yes is used
+func pruneStaleFiles(filesToCopy []FileCopyConfig, logger examples.Logger) error {
+	staleFiles, err := findStaleFiles(filesToCopy)
+	if err != nil {
+		return err
+	}
+
+	if len(staleFiles) == 0 {
+		logger.Infof("no stale files to prune in %s", outputPath)
+		return nil
+	}
+
+	for _, file := range staleFiles {
+		logger.Infof("stale file: %s", file)
+	}
+
+	if !*yesPtr && !confirm(fmt.Sprintf("remove %d stale file(s) from %s?", len(staleFiles), outputPath)) {
+		logger.Infof("skipped pruning %d stale file(s), use --yes to remove them without confirmation", len(staleFiles))
+		return nil
+	}
+
+	for _, file := range staleFiles {
+		if err := retryTransient(logger, func() error { return os.Remove(file) }); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+		if !*quietPtr {
+			logger.Infof("removed %s", file)
+		}
+	}
+	logger.Infof("pruned %d stale file(s) from %s", len(staleFiles), outputPath)
+
+	return nil
+}
+
+// findStaleFiles lists the files with the output file prefix under the type directories of the output
+// that are not copied from a current starter workflow, such as the files of deleted or deprecated workflows
+func findStaleFiles(filesToCopy []FileCopyConfig) ([]string, error) {
+	current := make(map[string]bool, len(filesToCopy))
+	for _, file := range filesToCopy {
+		current[path.Clean(file.Dest)] = true
+	}
+
+	dirs := map[string]bool{}
+	for _, typeDir := range typeDirs {
+		dirs[path.Join(outputPath, typeDir)] = true
+	}
+
+	stale := map[string]bool{}
+	for dir := range dirs {
+		err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+			// a type directory may not exist in the output
+			if err != nil && filePath == dir && os.IsNotExist(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			filePath = filepath.ToSlash(filePath)
+			if !d.IsDir() && strings.HasPrefix(d.Name(), outputFilePrefix+"-") && !current[filePath] {
+				stale[filePath] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+	}
+
+	staleFiles := make([]string, 0, len(stale))
+	for file := range stale {
+		staleFiles = append(staleFiles, file)
+	}
+	sort.Strings(staleFiles)
+
+	return staleFiles, nil
+}
+
+// confirm asks a yes or no question on stderr and reports whether it was answered with yes on stdin
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// loadProperties reads the properties file of a workflow
+func loadProperties(filePath string) (WorkflowProperties, error) {
+	var properties WorkflowProperties
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return properties, err
+	}
+
+	if err := json.Unmarshal(contents, &properties); err != nil {
+		return properties, fmt.Errorf("failed to unmarshal %s: %w", filePath, err)
+	}
+
+	return properties, nil
+}
+
+// planChanges groups the files to copy by whether their destination is missing, different or identical
+func planChanges(filesToCopy []FileCopyConfig) (map[string][]FileCopyConfig, error) {
+	changes := map[string][]FileCopyConfig{}
+	for _, file := range filesToCopy {
+		change := changeAdded
+		if _, err := os.Stat(file.Dest); err == nil {
+			sourceChecksum, err := fileChecksum(file.Source)
+			if err != nil {
+				return nil, err
+			}
+
+			destChecksum, err := fileChecksum(file.Dest)
+			if err != nil {
+				return nil, err
+			}
+
+			change = changeChanged
+			if sourceChecksum == destChecksum {
+				change = changeUnchanged
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to validate %s exists: %w", file.Dest, err)
+		}
+
+		changes[change] = append(changes[change], file)
+	}
+
+	for _, files := range changes {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Dest < files[j].Dest
+		})
+	}
+
+	return changes, nil
+}
+
+// printChangelog prints the planned changes grouped by change type, with quiet only the number of files of each
+func printChangelog(changes map[string][]FileCopyConfig, quiet bool) {
+	for _, change := range []string{changeAdded, changeChanged, changeUnchanged} {
+		fmt.Printf("%s (%d):\n", change, len(changes[change]))
+		if quiet {
+			continue
+		}
+		for _, file := range changes[change] {
+			fmt.Printf("  %s -> %s\n", file.Source, file.Dest)
+		}
+	}
+}
+
+// parseWorkflowFilter parses comma separated workflow IDs or glob patterns into the set of
+// matching starter workflow IDs, returning nil when there is no filter
+func parseWorkflowFilter(value string, workflowConfig examples.WorkflowConfig, workflowsDir string) (map[string]bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	filter := map[string]bool{}
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+
+		matched := false
+		for _, workflowID := range examples.SortedWorkflowIDs(workflowConfig) {
+			workflow := workflowConfig[workflowID]
+
+			if !workflow.Starter {
+				continue
+			}
+
+			ok, err := matchWorkflow(pattern, workflowID, workflow, workflowsDir)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			if ok {
+				filter[workflowID] = true
+				matched = true
+			}
+		}
+
+		if !matched {
+			return nil, fmt.Errorf("%q does not match any starter workflow in %s", pattern, workflowConfigPath)
+		}
+	}
+
+	return filter, nil
+}
+
+// matchWorkflow reports whether a glob pattern matches the workflow ID, the workflow path,
+// or the action-name/workflow-name of a workflow in workflowsDir
+func matchWorkflow(pattern string, workflowID string, workflow examples.Workflow, workflowsDir string) (bool, error) {
+	names := []string{workflowID, workflow.WorkflowPath}
+	if actionWorkflow, err := filepath.Rel(workflowsDir, path.Clean(workflow.WorkflowPath)); err == nil && !strings.HasPrefix(filepath.ToSlash(actionWorkflow), "../") {
+		names = append(names, strings.TrimSuffix(filepath.ToSlash(actionWorkflow), path.Ext(workflow.WorkflowPath)))
+	}
+
+	for _, name := range names {
+		ok, err := path.Match(pattern, name)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+// defaultTypeDirs returns the directory of each valid workflow type, named after the type
+func defaultTypeDirs() map[string]string {
+	dirs := map[string]string{}
+	for _, workflowType := range examples.SortedTypes() {
+		dirs[workflowType] = workflowType
+	}
+	return dirs
+}
+
+// parseTypeDirs overrides typeDirs with comma separated type=dir pairs
+func parseTypeDirs(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return fmt.Errorf("invalid type directory %q, expected type=dir", pair)
+		}
+		workflowType, dir := parts[0], parts[1]
+
+		if _, ok := typeDirs[workflowType]; !ok {
+			return fmt.Errorf("invalid type %q in type directory %q", workflowType, pair)
+		}
+		typeDirs[workflowType] = dir
+	}
+
+	return nil
+}
+
+// resolveTypeDir returns the starter workflows repository directory of a workflow type, types with
+// a slash such as ci/go are released into a subdirectory of the directory of their first segment
+func resolveTypeDir(workflowType string) (string, bool) {
+	parts := strings.Split(workflowType, "/")
+	typeDir, ok := typeDirs[parts[0]]
+	if !ok {
+		return "", false
+	}
+
+	for _, dir := range parts[1:] {
+		if dir == "" || dir == "." || dir == ".." {
+			return "", false
+		}
+	}
+
+	return path.Join(append([]string{typeDir}, parts[1:]...)...), true
+}
+
+// linkOrCopyFile hard links source to dest, copying the file contents instead
+// when they are on different filesystems
+func linkOrCopyFile(source string, dest string, logger examples.Logger) error {
+	err := retryTransient(logger, func() error { return linkFile(source, dest) })
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	contents, err := os.ReadFile(source)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	if err := retryTransient(logger, func() error { return os.WriteFile(dest, contents, 0644) }); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
 	}
 
 	return nil
 }
 
+// retryTransient runs a file operation, retrying it with exponential backoff while it fails with a
+// transient error, such as EAGAIN or EBUSY on networked filesystems. Other errors are returned immediately
+func retryTransient(logger examples.Logger, op func() error) error {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !isTransientError(err) || attempt >= retryAttempts {
+			return err
+		}
+
+		logger.Warnf("retrying after transient error (attempt %d of %d): %s", attempt, retryAttempts, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isTransientError reports whether a file operation error is likely to succeed when retried
+func isTransientError(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EINTR)
+}
+
+// verifyChecksum compares the SHA-256 checksums of the source and destination files,
+// returning the checksum when they match
+func verifyChecksum(file FileCopyConfig) (string, error) {
+	sourceChecksum, err := fileChecksum(file.Source)
+	if err != nil {
+		return "", err
+	}
+
+	destChecksum, err := fileChecksum(file.Dest)
+	if err != nil {
+		return "", err
+	}
+
+	if sourceChecksum != destChecksum {
+		return "", fmt.Errorf("checksum mismatch for %s -> %s: source %s, destination %s", file.Source, file.Dest, sourceChecksum, destChecksum)
+	}
+
+	return sourceChecksum, nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of a file
+func fileChecksum(filePath string) (string, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 func defaultEnv(key string, defaultValue string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -0,0 +1,1047 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+
+// Run with: go test scripts/release.go scripts/release_test.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google-github-actions/example-workflows/pkg/examples"
+)
+
+// testWorkflowContents is the workflow released by the tests
+const testWorkflowContents = `name: Deploy App
+
+on:
+  push:
+    branches:
+      - main
+
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`
+
+// testProperties is the properties file released by the tests
+const testProperties = `{"name": "Deploy App", "description": "Deploy an app to Cloud Run.", "categories": ["Cloud Run", "Deployment"]}` + "\n"
+
+// testRepoFiles returns the files of a repository with a single starter workflow
+func testRepoFiles() map[string]string {
+	return map[string]string{
+		"workflow.config.json": `{
+  "deploy-app": {
+    "starter": true,
+    "type": "deployments",
+    "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml",
+    "propertiesPath": "properties/deploy-app.properties.json"
+  }
+}
+`,
+		"workflows/deploy-cloudrun/deploy-app.yml": testWorkflowContents,
+		"properties/deploy-app.properties.json":    testProperties,
+	}
+}
+
+// newTestRepo writes the files of testRepoFiles, updated with files, to a temporary directory
+// and returns its path. A file with empty contents in files is not written.
+func newTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	repoFiles := testRepoFiles()
+	for name, contents := range files {
+		repoFiles[name] = contents
+	}
+
+	dir := t.TempDir()
+	for name, contents := range repoFiles {
+		if contents == "" {
+			continue
+		}
+		writeTestFile(t, filepath.Join(dir, name), contents)
+	}
+
+	return dir
+}
+
+// writeTestFile writes a file, creating its parent directories
+func writeTestFile(t *testing.T, filePath string, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readTestFile returns the contents of a file, failing the test when it cannot be read
+func readTestFile(t *testing.T, filePath string) string {
+	t.Helper()
+
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(contents)
+}
+
+// chdir changes the working directory to dir for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// resetFlags sets every flag of the script back to its default and forgets which flags were set,
+// so each test parses its flags as a fresh run of the script would
+func resetFlags() {
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	flag.CommandLine.VisitAll(func(f *flag.Flag) {
+		// the flags of go test keep their values
+		if !strings.HasPrefix(f.Name, "test.") {
+			_ = f.Value.Set(f.DefValue)
+		}
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	flag.CommandLine = fs
+}
+
+// runRelease runs the script with args in dir, releasing into the returned output directory
+// unless --output is passed, and returns what it printed to stdout and logged
+func runRelease(t *testing.T, dir string, args ...string) (string, string, string, error) {
+	t.Helper()
+
+	outputDir := filepath.Join(t.TempDir(), "starter-workflows")
+
+	chdir(t, dir)
+	resetFlags()
+	workflowConfigPath = "workflow.config.json"
+
+	// realMain overrides typeDirs with TYPE_DIRS
+	originalTypeDirs := make(map[string]string, len(typeDirs))
+	for workflowType, dir := range typeDirs {
+		originalTypeDirs[workflowType] = dir
+	}
+	t.Cleanup(func() { typeDirs = originalTypeDirs })
+	if err := flag.CommandLine.Parse(append([]string{"--output=" + outputDir}, args...)); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	var err error
+	stdout := captureStdout(t, func() {
+		err = realMain(context.Background(), examples.NewLogger(&logs, examples.LevelInfo))
+	})
+
+	return outputDir, stdout, logs.String(), err
+}
+
+// captureStdout returns what fn printed to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	stdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+
+	return readTestFile(t, file.Name())
+}
+
+// stubLinkFile replaces linkFile for the rest of the test
+func stubLinkFile(t *testing.T, link func(string, string) error) {
+	t.Helper()
+
+	original := linkFile
+	linkFile = link
+	t.Cleanup(func() { linkFile = original })
+}
+
+func TestReleaseLinkFallback(t *testing.T) {
+	cases := []struct {
+		name     string
+		link     func(string, string) error
+		wantLink bool
+	}{
+		{
+			name:     "link",
+			link:     os.Link,
+			wantLink: true,
+		},
+		{
+			name: "cross_device",
+			link: func(oldname string, newname string) error {
+				return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: syscall.EXDEV}
+			},
+			wantLink: false,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			stubLinkFile(t, tc.link)
+
+			outputDir, _, _, err := runRelease(t, dir)
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			for source, dest := range map[string]string{
+				"workflows/deploy-cloudrun/deploy-app.yml": "deployments/google-deploy-app.yml",
+				"properties/deploy-app.properties.json":    "deployments/properties/google-deploy-app.properties.json",
+			} {
+				sourcePath, destPath := filepath.Join(dir, source), filepath.Join(outputDir, dest)
+				if got, want := readTestFile(t, destPath), readTestFile(t, sourcePath); got != want {
+					t.Errorf("release copied %s to %s with contents:\n%s\nwant:\n%s", source, dest, got, want)
+				}
+
+				sourceInfo, err := os.Stat(sourcePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				destInfo, err := os.Stat(destPath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := os.SameFile(sourceInfo, destInfo); got != tc.wantLink {
+					t.Errorf("release linked %s to %s: %t, want %t", source, dest, got, tc.wantLink)
+				}
+				if !tc.wantLink && destInfo.Mode().Perm() != 0o644 {
+					t.Errorf("release copied %s with mode %s, want 0644", dest, destInfo.Mode().Perm())
+				}
+			}
+		})
+	}
+}
+
+func TestReleaseChecksum(t *testing.T) {
+	sourceSum := sha256.Sum256([]byte(testWorkflowContents))
+	tamperedSum := sha256.Sum256([]byte(testWorkflowContents + "# tampered\n"))
+
+	cases := []struct {
+		name     string
+		link     func(string, string) error
+		wantErr  string
+		wantLogs string // contained in the logs, or in the error when wantErr is set
+	}{
+		{
+			name:     "verified",
+			link:     os.Link,
+			wantLogs: "(sha256: " + hex.EncodeToString(sourceSum[:]) + ")",
+		},
+		{
+			name: "tampered",
+			link: func(oldname string, newname string) error {
+				contents, err := os.ReadFile(oldname)
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(newname, append(contents, "# tampered\n"...), 0o644)
+			},
+			wantErr:  "failed to verify copied file: checksum mismatch for workflows/deploy-cloudrun/deploy-app.yml -> ",
+			wantLogs: "source " + hex.EncodeToString(sourceSum[:]) + ", destination " + hex.EncodeToString(tamperedSum[:]),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			stubLinkFile(t, tc.link)
+
+			_, _, logs, err := runRelease(t, dir)
+			if tc.wantErr == "" && err != nil {
+				t.Fatalf("release: %s", err)
+			}
+			if tc.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.wantErr) {
+					t.Fatalf("release error = %v, want %s...", err, tc.wantErr)
+				}
+				logs = err.Error()
+			}
+			if !strings.Contains(logs, tc.wantLogs) {
+				t.Errorf("release reported %q, want it to contain %q", logs, tc.wantLogs)
+			}
+		})
+	}
+}
+
+func TestReleaseOutputFilePrefix(t *testing.T) {
+	cases := []struct {
+		name      string
+		prefix    string
+		wantDests []string
+	}{
+		{
+			name:      "default",
+			wantDests: []string{"deployments/google-deploy-app.yml", "deployments/properties/google-deploy-app.properties.json"},
+		},
+		{
+			name:      "custom",
+			prefix:    "acme",
+			wantDests: []string{"deployments/acme-deploy-app.yml", "deployments/properties/acme-deploy-app.properties.json"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.prefix != "" {
+				t.Setenv("OUTPUT_FILE_PREFIX", tc.prefix)
+			}
+			dir := newTestRepo(t, nil)
+
+			outputDir, stdout, _, err := runRelease(t, dir)
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			for _, dest := range tc.wantDests {
+				destPath := filepath.Join(outputDir, dest)
+				if !strings.Contains(stdout, " -> "+destPath+"\n") {
+					t.Errorf("release printed changelog:\n%s\nwant it to copy to %s", stdout, destPath)
+				}
+				if _, err := os.Stat(destPath); err != nil {
+					t.Errorf("release did not write %s: %s", dest, err)
+				}
+			}
+		})
+	}
+}
+
+func TestReleaseTypes(t *testing.T) {
+	cases := []struct {
+		name         string
+		workflowType string
+		typeDirs     string // TYPE_DIRS, unset when empty
+		wantDest     string
+		wantErr      string
+		wantLogs     string
+	}{
+		{
+			name:         "valid",
+			workflowType: "deployments",
+			wantDest:     "deployments/google-deploy-app.yml",
+		},
+		{
+			name:         "invalid",
+			workflowType: "deploymentz",
+			wantErr:      "failed to process invalid configs",
+			wantLogs:     "error: invalid type for workflow deploy-app: type - deploymentz\n",
+		},
+		{
+			name:         "custom_mapping",
+			workflowType: "deployments",
+			typeDirs:     "deployments=deploy",
+			wantDest:     "deploy/google-deploy-app.yml",
+		},
+		{
+			name:         "invalid_mapping",
+			workflowType: "deployments",
+			typeDirs:     "deploymentz=deploy",
+			wantErr:      `failed to parse TYPE_DIRS: invalid type "deploymentz" in type directory "deploymentz=deploy"`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.typeDirs != "" {
+				t.Setenv("TYPE_DIRS", tc.typeDirs)
+			}
+			dir := newTestRepo(t, map[string]string{
+				"workflow.config.json": strings.Replace(testRepoFiles()["workflow.config.json"], `"deployments"`, `"`+tc.workflowType+`"`, 1),
+			})
+
+			outputDir, _, logs, err := runRelease(t, dir)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("release error = %v, want %s", err, tc.wantErr)
+				}
+				if !strings.Contains(logs, tc.wantLogs) {
+					t.Errorf("release logged:\n%s\nwant it to contain:\n%s", logs, tc.wantLogs)
+				}
+				if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+					t.Errorf("release created %s for an invalid type", outputDir)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			if _, err := os.Stat(filepath.Join(outputDir, tc.wantDest)); err != nil {
+				t.Errorf("release did not write %s: %s", tc.wantDest, err)
+			}
+		})
+	}
+}
+
+func TestReleaseWorkflowFilter(t *testing.T) {
+	files := map[string]string{
+		"workflow.config.json": `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-other": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-other.yml", "propertiesPath": "properties/deploy-other.properties.json"}
+}
+`,
+		"workflows/deploy-cloudrun/deploy-other.yml": testWorkflowContents,
+		"properties/deploy-other.properties.json":    testProperties,
+	}
+
+	cases := []struct {
+		name      string
+		filter    string
+		wantFiles []string
+		wantErr   string
+	}{
+		{
+			name:      "unfiltered",
+			wantFiles: []string{"deployments/google-deploy-app.yml", "deployments/google-deploy-other.yml", "deployments/properties/google-deploy-app.properties.json", "deployments/properties/google-deploy-other.properties.json"},
+		},
+		{
+			name:      "one_workflow",
+			filter:    "deploy-app",
+			wantFiles: []string{"deployments/google-deploy-app.yml", "deployments/properties/google-deploy-app.properties.json"},
+		},
+		{
+			name:    "unknown_workflow",
+			filter:  "deploy-app,deploy-missing",
+			wantErr: `failed to parse WORKFLOW_FILTER: "deploy-missing" does not match any starter workflow in workflow.config.json`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.filter != "" {
+				t.Setenv("WORKFLOW_FILTER", tc.filter)
+			}
+			dir := newTestRepo(t, files)
+
+			outputDir, stdout, _, err := runRelease(t, dir)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("release error = %v, want %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			copied := make([]string, 0)
+			for _, line := range strings.Split(stdout, "\n") {
+				if _, dest, ok := strings.Cut(line, " -> "); ok {
+					rel, err := filepath.Rel(outputDir, dest)
+					if err != nil {
+						t.Fatal(err)
+					}
+					copied = append(copied, filepath.ToSlash(rel))
+				}
+			}
+			sort.Strings(copied)
+			if !reflect.DeepEqual(copied, tc.wantFiles) {
+				t.Errorf("release copied %q, want %q", copied, tc.wantFiles)
+			}
+		})
+	}
+}
+
+func TestReleaseUnchangedDestination(t *testing.T) {
+	dir := newTestRepo(t, nil)
+	outputDir := filepath.Join(t.TempDir(), "starter-workflows")
+	workflowDest := filepath.Join(outputDir, "deployments", "google-deploy-app.yml")
+	propertiesDest := filepath.Join(outputDir, "deployments", "properties", "google-deploy-app.properties.json")
+	writeTestFile(t, workflowDest, testWorkflowContents)
+	writeTestFile(t, propertiesDest, "{}\n")
+
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(workflowDest, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stdout, logs, err := runRelease(t, dir, "--output="+outputDir)
+	if err != nil {
+		t.Fatalf("release: %s", err)
+	}
+
+	wantChangelog := "added (0):\n" +
+		"changed (1):\n" +
+		"  properties/deploy-app.properties.json -> " + propertiesDest + "\n" +
+		"unchanged (1):\n" +
+		"  workflows/deploy-cloudrun/deploy-app.yml -> " + workflowDest + "\n"
+	if stdout != wantChangelog {
+		t.Errorf("release printed changelog:\n%s\nwant:\n%s", stdout, wantChangelog)
+	}
+	if want := "copied 1 file(s) to " + outputDir + ", 1 unchanged"; !strings.Contains(logs, want) {
+		t.Errorf("release logged:\n%s\nwant it to contain %q", logs, want)
+	}
+
+	info, err := os.Stat(workflowDest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("release rewrote the unchanged %s, modified at %s, want %s", workflowDest, info.ModTime(), modTime)
+	}
+	if got := readTestFile(t, propertiesDest); got != testProperties {
+		t.Errorf("release wrote %s:\n%s\nwant:\n%s", propertiesDest, got, testProperties)
+	}
+}
+
+func TestReleaseWorkflowFilterGlob(t *testing.T) {
+	files := map[string]string{
+		"workflow.config.json": `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-other": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-other.yml", "propertiesPath": "properties/deploy-other.properties.json"},
+  "auth-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/auth/auth-app.yml", "propertiesPath": "properties/auth-app.properties.json"},
+  "gke-deploy": {"starter": true, "type": "deployments", "workflowPath": "examples/deploy-gke/gke-deploy.yml", "propertiesPath": "properties/gke-deploy.properties.json"}
+}
+`,
+		"workflows/deploy-cloudrun/deploy-other.yml": testWorkflowContents,
+		"properties/deploy-other.properties.json":    testProperties,
+		"workflows/auth/auth-app.yml":                testWorkflowContents,
+		"properties/auth-app.properties.json":        testProperties,
+		"examples/deploy-gke/gke-deploy.yml":         testWorkflowContents,
+		"properties/gke-deploy.properties.json":      testProperties,
+	}
+
+	cases := []struct {
+		name          string
+		filter        string
+		args          []string
+		wantWorkflows []string
+		wantErr       string
+	}{
+		{
+			name:          "action_glob",
+			filter:        "deploy-cloudrun/*",
+			wantWorkflows: []string{"deployments/google-deploy-app.yml", "deployments/google-deploy-other.yml"},
+		},
+		{
+			name:          "id_glob",
+			filter:        "*-app",
+			wantWorkflows: []string{"deployments/google-auth-app.yml", "deployments/google-deploy-app.yml"},
+		},
+		{
+			name:          "exact_id",
+			filter:        "auth-app",
+			wantWorkflows: []string{"deployments/google-auth-app.yml"},
+		},
+		{
+			name:    "no_match",
+			filter:  "deploy-gke/*",
+			wantErr: `failed to parse WORKFLOW_FILTER: "deploy-gke/*" does not match any starter workflow in workflow.config.json`,
+		},
+		{
+			// action-name/workflow-name is relative to the workflows directory
+			name:          "workflows_dir",
+			filter:        "deploy-gke/*",
+			args:          []string{"--workflows-dir", "examples"},
+			wantWorkflows: []string{"deployments/google-gke-deploy.yml"},
+		},
+		{
+			name:    "workflows_dir_no_match",
+			filter:  "deploy-cloudrun/*",
+			args:    []string{"--workflows-dir", "examples"},
+			wantErr: `failed to parse WORKFLOW_FILTER: "deploy-cloudrun/*" does not match any starter workflow in workflow.config.json`,
+		},
+		{
+			name:          "workflow_path",
+			filter:        "examples/*/*.yml",
+			wantWorkflows: []string{"deployments/google-gke-deploy.yml"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("WORKFLOW_FILTER", tc.filter)
+			dir := newTestRepo(t, files)
+
+			outputDir, _, _, err := runRelease(t, dir, tc.args...)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("release error = %v, want %s", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			got, err := filepath.Glob(filepath.Join(outputDir, "deployments", "*.yml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				got[i] = filepath.ToSlash(strings.TrimPrefix(got[i], outputDir+string(filepath.Separator)))
+			}
+			if !reflect.DeepEqual(got, tc.wantWorkflows) {
+				t.Errorf("release copied %q, want %q", got, tc.wantWorkflows)
+			}
+		})
+	}
+}
+
+func TestReleaseDeprecated(t *testing.T) {
+	dir := newTestRepo(t, map[string]string{
+		"properties/deploy-app.properties.json": `{"name": "Deploy App", "description": "Deploy an app to Cloud Run.", "categories": ["Cloud Run"], "deprecated": true}` + "\n",
+	})
+
+	outputDir, stdout, logs, err := runRelease(t, dir)
+	if err != nil {
+		t.Fatalf("release: %s", err)
+	}
+
+	if want := "skipping deprecated starter workflow deploy-app\n"; !strings.Contains(logs, want) {
+		t.Errorf("release logged:\n%s\nwant it to contain %q", logs, want)
+	}
+	if want := "added (0):\nchanged (0):\nunchanged (0):\n"; stdout != want {
+		t.Errorf("release printed changelog:\n%s\nwant:\n%s", stdout, want)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "deployments", "google-deploy-app.yml")); !os.IsNotExist(err) {
+		t.Errorf("release copied the deprecated workflow: %v", err)
+	}
+}
+
+func TestRetryTransient(t *testing.T) {
+	transientErr := &os.PathError{Op: "link", Path: "dest", Err: syscall.EBUSY}
+	notFoundErr := &os.PathError{Op: "link", Path: "dest", Err: syscall.ENOENT}
+
+	cases := []struct {
+		name      string
+		errs      []error // returned by the successive calls, nil once exhausted
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "transient_then_success",
+			errs:      []error{transientErr},
+			wantCalls: 2,
+		},
+		{
+			name:      "always_transient",
+			errs:      []error{transientErr, transientErr, transientErr, transientErr, transientErr, transientErr},
+			wantCalls: retryAttempts,
+			wantErr:   transientErr,
+		},
+		{
+			name:      "not_transient",
+			errs:      []error{notFoundErr},
+			wantCalls: 1,
+			wantErr:   notFoundErr,
+		},
+	}
+
+	originalDelay := retryDelay
+	retryDelay = 0
+	t.Cleanup(func() { retryDelay = originalDelay })
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			var logs bytes.Buffer
+			err := retryTransient(examples.NewLogger(&logs, examples.LevelInfo), func() error {
+				calls++
+				if calls <= len(tc.errs) {
+					return tc.errs[calls-1]
+				}
+				return nil
+			})
+
+			if err != tc.wantErr {
+				t.Errorf("retryTransient error = %v, want %v", err, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("retryTransient called the operation %d time(s), want %d", calls, tc.wantCalls)
+			}
+			if got, want := strings.Count(logs.String(), "retrying after transient error"), tc.wantCalls-1; got != want {
+				t.Errorf("retryTransient logged %d retries, want %d:\n%s", got, want, logs.String())
+			}
+		})
+	}
+}
+
+func TestReleaseQuiet(t *testing.T) {
+	cases := []struct {
+		name      string
+		args      []string
+		wantFiles int // the number of per-file lines printed and logged
+	}{
+		{
+			name:      "verbose",
+			wantFiles: 2,
+		},
+		{
+			name: "quiet",
+			args: []string{"--quiet"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+
+			outputDir, stdout, logs, err := runRelease(t, dir, tc.args...)
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			if got := strings.Count(stdout, " -> "); got != tc.wantFiles {
+				t.Errorf("release printed %d planned file(s), want %d:\n%s", got, tc.wantFiles, stdout)
+			}
+			for _, change := range []string{"added (2):", "changed (0):", "unchanged (0):"} {
+				if !strings.Contains(stdout, change+"\n") {
+					t.Errorf("release printed:\n%s\nwant the count %s", stdout, change)
+				}
+			}
+
+			if got := strings.Count(logs, "successfully copied "); got != tc.wantFiles {
+				t.Errorf("release logged %d copied file(s), want %d:\n%s", got, tc.wantFiles, logs)
+			}
+			if summary := "copied 2 file(s) to " + outputDir + ", 0 unchanged\n"; !strings.Contains(logs, summary) {
+				t.Errorf("release logged:\n%s\nwant the summary %s", logs, summary)
+			}
+		})
+	}
+}
+
+func TestReleaseNestedType(t *testing.T) {
+	cases := []struct {
+		name      string
+		typeDirs  string // TYPE_DIRS, unset when empty
+		wantFiles []string
+	}{
+		{
+			name: "nested",
+			wantFiles: []string{
+				"ci/go/google-deploy-app.yml",
+				"ci/go/properties/google-deploy-app.properties.json",
+			},
+		},
+		{
+			name:     "custom_mapping",
+			typeDirs: "ci=ci-custom",
+			wantFiles: []string{
+				"ci-custom/go/google-deploy-app.yml",
+				"ci-custom/go/properties/google-deploy-app.properties.json",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.typeDirs != "" {
+				t.Setenv("TYPE_DIRS", tc.typeDirs)
+			}
+			dir := newTestRepo(t, map[string]string{
+				"workflow.config.json": strings.Replace(testRepoFiles()["workflow.config.json"], `"deployments"`, `"ci/go"`, 1),
+			})
+
+			// the nested directories do not exist in the output yet
+			outputDir, _, _, err := runRelease(t, dir)
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			for _, name := range tc.wantFiles {
+				if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+					t.Errorf("release did not write %s: %s", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestReleaseEmptyOutput(t *testing.T) {
+	dir := newTestRepo(t, nil)
+
+	// the output exists but has none of the type and properties directories
+	outputDir := t.TempDir()
+	_, _, _, err := runRelease(t, dir, "--output="+outputDir)
+	if err != nil {
+		t.Fatalf("release: %s", err)
+	}
+
+	for _, name := range []string{
+		"deployments/google-deploy-app.yml",
+		"deployments/properties/google-deploy-app.properties.json",
+	} {
+		if got := readTestFile(t, filepath.Join(outputDir, name)); got == "" {
+			t.Errorf("release wrote an empty %s", name)
+		}
+	}
+}
+
+func TestReleaseCaseCollision(t *testing.T) {
+	cases := []struct {
+		name     string
+		config   string
+		wantErr  bool
+		wantLogs string
+	}{
+		{
+			name: "clean",
+			config: `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-other": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-gke/deploy-other.yml", "propertiesPath": "properties/deploy-other.properties.json"}
+}
+`,
+		},
+		{
+			name: "case_only",
+			config: `{
+  "deploy-app": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-cloudrun/deploy-app.yml", "propertiesPath": "properties/deploy-app.properties.json"},
+  "deploy-other": {"starter": true, "type": "deployments", "workflowPath": "workflows/deploy-gke/Deploy-App.yml", "propertiesPath": "properties/deploy-other.properties.json"}
+}
+`,
+			wantErr:  true,
+			wantLogs: "error: destination of workflow deploy-other collides with workflow deploy-app on case-insensitive filesystems: path - ",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, map[string]string{
+				"workflow.config.json":                    tc.config,
+				"workflows/deploy-gke/deploy-other.yml":   testWorkflowContents,
+				"workflows/deploy-gke/Deploy-App.yml":     testWorkflowContents,
+				"properties/deploy-other.properties.json": testProperties,
+			})
+
+			outputDir, _, logs, err := runRelease(t, dir)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("release returned %v, want an error: %t", err, tc.wantErr)
+			}
+			if !strings.Contains(logs, tc.wantLogs) {
+				t.Errorf("release logged:\n%s\nwant %s", logs, tc.wantLogs)
+			}
+
+			// nothing is copied when two destinations collide
+			if _, statErr := os.Stat(outputDir); tc.wantErr && !os.IsNotExist(statErr) {
+				t.Errorf("release created %s, want nothing copied", outputDir)
+			}
+		})
+	}
+}
+
+func TestReleaseOutputFlag(t *testing.T) {
+	cases := []struct {
+		name      string
+		output    string // relative to the repository unless absolute
+		wantDests []string
+	}{
+		{
+			name:   "absolute",
+			output: filepath.Join(t.TempDir(), "custom-output"),
+		},
+		{
+			name:   "relative_unclean",
+			output: "build/../starter-workflows/",
+			wantDests: []string{
+				"starter-workflows/deployments/google-deploy-app.yml",
+				"starter-workflows/deployments/properties/google-deploy-app.properties.json",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+
+			defaultOutputDir, stdout, _, err := runRelease(t, dir, "--output="+tc.output)
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			wantDests := tc.wantDests
+			if wantDests == nil {
+				wantDests = []string{
+					filepath.Join(tc.output, "deployments", "google-deploy-app.yml"),
+					filepath.Join(tc.output, "deployments", "properties", "google-deploy-app.properties.json"),
+				}
+			}
+			for _, dest := range wantDests {
+				if !strings.Contains(stdout, " -> "+dest+"\n") {
+					t.Errorf("release printed changelog:\n%s\nwant the destination %s", stdout, dest)
+				}
+				destPath := dest
+				if !filepath.IsAbs(destPath) {
+					destPath = filepath.Join(dir, dest)
+				}
+				if _, err := os.Stat(destPath); err != nil {
+					t.Errorf("release did not copy to %s: %s", dest, err)
+				}
+			}
+
+			// the output passed before by runRelease is overridden
+			if _, err := os.Stat(defaultOutputDir); !os.IsNotExist(err) {
+				t.Errorf("release created %s, want only the --output directory", defaultOutputDir)
+			}
+		})
+	}
+}
+
+// stubStdin replaces os.Stdin with input for the rest of the test
+func stubStdin(t *testing.T, input string) {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "stdin")
+	writeTestFile(t, filePath, input)
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = file
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		file.Close()
+	})
+}
+
+func TestReleasePrune(t *testing.T) {
+	cases := []struct {
+		name      string
+		args      []string
+		stdin     string
+		wantStale bool
+	}{
+		{
+			name: "yes",
+			args: []string{"--prune", "--yes"},
+		},
+		{
+			name:  "confirmed",
+			args:  []string{"--prune"},
+			stdin: "y\n",
+		},
+		{
+			name:      "declined",
+			args:      []string{"--prune"},
+			stdin:     "n\n",
+			wantStale: true,
+		},
+		{
+			name:      "no_prune",
+			wantStale: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			dir := newTestRepo(t, nil)
+			stubStdin(t, tc.stdin)
+
+			// the confirmation question is asked on stderr
+			devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stderr := os.Stderr
+			os.Stderr = devNull
+			t.Cleanup(func() {
+				os.Stderr = stderr
+				devNull.Close()
+			})
+
+			outputDir := filepath.Join(t.TempDir(), "starter-workflows")
+			current := filepath.Join(outputDir, "deployments", "google-deploy-app.yml")
+			stale := []string{
+				filepath.Join(outputDir, "deployments", "google-deleted-app.yml"),
+				filepath.Join(outputDir, "deployments", "properties", "google-deleted-app.properties.json"),
+			}
+			// files without the output file prefix are not managed by release
+			unmanaged := filepath.Join(outputDir, "deployments", "azure-webapps.yml")
+			for _, filePath := range append([]string{current, unmanaged}, stale...) {
+				writeTestFile(t, filePath, "stale\n")
+			}
+
+			_, _, logs, err := runRelease(t, dir, append([]string{"--output=" + outputDir}, tc.args...)...)
+			if err != nil {
+				t.Fatalf("release: %s", err)
+			}
+
+			for _, filePath := range stale {
+				if _, err := os.Stat(filePath); tc.wantStale != (err == nil) {
+					t.Errorf("release left %s: %t, want %t\n%s", filePath, err == nil, tc.wantStale, logs)
+				}
+			}
+			if got := readTestFile(t, current); got != testWorkflowContents {
+				t.Errorf("release wrote %s:\n%s\nwant:\n%s", current, got, testWorkflowContents)
+			}
+			if got := readTestFile(t, unmanaged); got != "stale\n" {
+				t.Errorf("release changed the unmanaged %s to %q", unmanaged, got)
+			}
+		})
+	}
+}